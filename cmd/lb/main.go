@@ -26,6 +26,7 @@ func main() {
 	// 创建服务管理器
 	serverMgr := server.NewServerManager()
 	serverMgr.CreateFromConfig(cfg)
+	serverMgr.SetConfigPath(*configPath)
 
 	// 启动服务
 	serverMgr.StartAll()
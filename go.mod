@@ -3,6 +3,7 @@ module go-load-balancer
 go 1.24
 
 require (
+	github.com/go-viper/mapstructure/v2 v2.2.1
 	github.com/prometheus/client_golang v1.19.0
 	golang.org/x/sys v0.32.0
 )
@@ -11,7 +12,6 @@ require (
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/fsnotify/fsnotify v1.8.0 // indirect
-	github.com/go-viper/mapstructure/v2 v2.2.1 // indirect
 	github.com/pelletier/go-toml/v2 v2.2.3 // indirect
 	github.com/prometheus/client_model v0.6.0 // indirect
 	github.com/prometheus/common v0.51.0 // indirect
@@ -0,0 +1,229 @@
+package algorithms
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/stats"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// DefaultVirtualNodes 每单位权重的默认虚拟节点数
+const DefaultVirtualNodes = 160
+
+// ringNode 哈希环上的一个虚拟节点
+type ringNode struct {
+	hash    uint32
+	backend int // 在后端列表中的下标
+}
+
+// hashRing ketama风格的一致性哈希环
+//
+// 环在创建后不再修改，查找时无需加锁。后端宕机时只跳过它的虚拟节点，
+// 因此只有原本落在该后端上的键会被重新映射。
+type hashRing struct {
+	nodes    []ringNode
+	backends []*backend.Backend
+}
+
+// newHashRing 根据后端列表构建哈希环，虚拟节点数按后端权重放大
+func newHashRing(backends []*backend.Backend, virtualNodes int) *hashRing {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	r := &hashRing{backends: backends}
+	for i, b := range backends {
+		weight := weightOf(b)
+
+		// 每次md5计算产生4个虚拟节点
+		points := virtualNodes * weight
+		for j := 0; j < (points+3)/4; j++ {
+			digest := md5.Sum([]byte(b.URL.Host + "-" + strconv.Itoa(j)))
+			for k := 0; k < 4 && j*4+k < points; k++ {
+				r.nodes = append(r.nodes, ringNode{
+					hash:    binary.LittleEndian.Uint32(digest[k*4:]),
+					backend: i,
+				})
+			}
+		}
+	}
+
+	sort.Slice(r.nodes, func(i, j int) bool {
+		return r.nodes[i].hash < r.nodes[j].hash
+	})
+	return r
+}
+
+// ketamaHash 计算键在环上的位置
+func ketamaHash(key string) uint32 {
+	digest := md5.Sum([]byte(key))
+	return binary.LittleEndian.Uint32(digest[:4])
+}
+
+// search 返回环上第一个哈希值不小于hash的虚拟节点下标
+func (r *hashRing) search(hash uint32) int {
+	i := sort.Search(len(r.nodes), func(i int) bool {
+		return r.nodes[i].hash >= hash
+	})
+	if i == len(r.nodes) {
+		i = 0
+	}
+	return i
+}
+
+// walk 从hash所在位置开始顺时针遍历环，依次对每个不同的后端调用visit，
+// visit返回true时停止遍历
+func (r *hashRing) walk(hash uint32, visit func(b *backend.Backend) bool) {
+	if len(r.nodes) == 0 {
+		return
+	}
+
+	seen := make([]bool, len(r.backends))
+	remaining := len(r.backends)
+	start := r.search(hash)
+	for i := 0; i < len(r.nodes) && remaining > 0; i++ {
+		node := r.nodes[(start+i)%len(r.nodes)]
+		if seen[node.backend] {
+			continue
+		}
+		seen[node.backend] = true
+		remaining--
+		if visit(r.backends[node.backend]) {
+			return
+		}
+	}
+}
+
+// ConsistentHashOptions 一致性哈希算法参数
+type ConsistentHashOptions struct {
+	// HashKey 哈希键，如 ip、header:X-User-ID、cookie:session、query:uid、path
+	HashKey string `mapstructure:"hash_key"`
+	// VirtualNodes 每单位权重的虚拟节点数
+	VirtualNodes int `mapstructure:"virtual_nodes"`
+	// LoadFactor 有界负载系数，0表示不限制
+	LoadFactor float64 `mapstructure:"load_factor"`
+}
+
+func init() {
+	RegisterAlgorithm("consistent_hash", func(backends []*backend.Backend, opts Options) (Algorithm, error) {
+		var o ConsistentHashOptions
+		if err := opts.Decode(&o); err != nil {
+			return nil, err
+		}
+		hashKey, err := ParseHashKey(o.HashKey)
+		if err != nil {
+			return nil, err
+		}
+		if o.VirtualNodes < 0 {
+			return nil, fmt.Errorf("虚拟节点数不能为负数: %d", o.VirtualNodes)
+		}
+		if o.LoadFactor != 0 && o.LoadFactor < 1 {
+			return nil, fmt.Errorf("有界负载系数必须不小于1: %v", o.LoadFactor)
+		}
+		return NewConsistentHash(backends, hashKey, o.VirtualNodes, o.LoadFactor), nil
+	})
+}
+
+// ConsistentHash 实现基于虚拟节点的一致性哈希负载均衡算法
+//
+// loadFactor大于0时启用有界负载模式(Consistent Hashing with Bounded Loads)：
+// 后端的连接数超过存活后端平均连接数的loadFactor倍时，键会沿环溢出到下一个后端。
+type ConsistentHash struct {
+	ring       *hashRing
+	hashKey    HashKey
+	loadFactor float64
+}
+
+// NewConsistentHash 创建新的一致性哈希算法实例，loadFactor为0时不限制负载
+func NewConsistentHash(backends []*backend.Backend, hashKey HashKey, virtualNodes int, loadFactor float64) Algorithm {
+	return &ConsistentHash{
+		ring:       newHashRing(backends, virtualNodes),
+		hashKey:    hashKey,
+		loadFactor: loadFactor,
+	}
+}
+
+// Pick 根据请求的哈希键在环上顺时针查找第一个存活的后端
+func (ch *ConsistentHash) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	if len(ch.ring.backends) == 0 {
+		return nil, ErrNoBackends
+	}
+	if req == nil {
+		return nil, ErrNilRequest
+	}
+
+	hash := ketamaHash(ch.hashKey.Extract(req))
+	if ch.loadFactor > 0 {
+		return ch.pickBounded(hash)
+	}
+
+	var picked *backend.Backend
+	ch.ring.walk(hash, func(b *backend.Backend) bool {
+		if b.IsAlive() {
+			picked = b
+			return true
+		}
+		return false
+	})
+
+	if picked == nil {
+		return nil, ErrNoAliveBackend
+	}
+	return picked, nil
+}
+
+// pickBounded 在有界负载模式下选择后端
+func (ch *ConsistentHash) pickBounded(hash uint32) (*backend.Backend, error) {
+	// 统计存活后端的连接总数，计算每个后端允许的最大连接数
+	var alive int
+	var total int64
+	for _, b := range ch.ring.backends {
+		if b.IsAlive() {
+			alive++
+			total += b.GetConnections()
+		}
+	}
+	if alive == 0 {
+		return nil, ErrNoAliveBackend
+	}
+	// 计入当前请求，保证至少有一个后端未超过上限
+	capacity := int64(math.Ceil(float64(total+1) / float64(alive) * ch.loadFactor))
+
+	var first, picked *backend.Backend
+	ch.ring.walk(hash, func(b *backend.Backend) bool {
+		if !b.IsAlive() {
+			return false
+		}
+		if first == nil {
+			first = b
+		}
+		if b.GetConnections() < capacity {
+			picked = b
+			return true
+		}
+		return false
+	})
+
+	// 连接数在统计后发生变化时可能找不到未超限的后端，退回到键的首选后端
+	if picked == nil {
+		picked = first
+	}
+	if picked == nil {
+		return nil, ErrNoAliveBackend
+	}
+	if picked != first {
+		stats.GetPrometheusCollector().RecordHashSpill(ch.Name())
+	}
+	return picked, nil
+}
+
+// Name 返回算法名称
+func (ch *ConsistentHash) Name() string {
+	return "consistent_hash"
+}
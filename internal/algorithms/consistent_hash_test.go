@@ -0,0 +1,122 @@
+package algorithms
+
+import (
+	"go-load-balancer/internal/backend"
+	"math"
+	"net/http"
+	"testing"
+)
+
+// newTestConsistentHash 创建按X-Key请求头哈希的一致性哈希算法
+func newTestConsistentHash(t *testing.T, backends []*backend.Backend, loadFactor float64) Algorithm {
+	t.Helper()
+	hashKey, err := ParseHashKey("header:X-Key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewConsistentHash(backends, hashKey, 0, loadFactor)
+}
+
+func TestConsistentHashRemoveRemapsOnlyRemovedKeys(t *testing.T) {
+	const n = 10
+	reqs := keyedRequests(20000)
+	backends := newTestBackends(t, n)
+	ch := newTestConsistentHash(t, backends, 0)
+	before := pickAll(t, ch, reqs)
+
+	removed := backends[3]
+	removed.SetAlive(false)
+	after := pickAll(t, ch, reqs)
+
+	moved := 0
+	for i := range reqs {
+		switch {
+		case before[i] == removed:
+			moved++
+			if after[i] == removed {
+				t.Fatal("键仍然映射到已下线的后端")
+			}
+		case after[i] != before[i]:
+			t.Fatalf("键 %s 原本不在下线的后端上，却从 %s 迁移到了 %s",
+				reqs[i].Header.Get("X-Key"), before[i].URL.Host, after[i].URL.Host)
+		}
+	}
+	if ratio := float64(moved) / float64(len(reqs)); ratio < 0.5/n || ratio > 1.5/n {
+		t.Errorf("下线后端上的键占比为 %.4f，期望接近 %.4f", ratio, 1.0/n)
+	}
+}
+
+func TestConsistentHashAddRemapsOnlyToNewNode(t *testing.T) {
+	const n = 10
+	reqs := keyedRequests(20000)
+	backends := newTestBackends(t, n+1)
+	before := pickAll(t, newTestConsistentHash(t, backends[:n], 0), reqs)
+	after := pickAll(t, newTestConsistentHash(t, backends, 0), reqs)
+
+	added := backends[n]
+	moved := 0
+	for i := range reqs {
+		if after[i] == before[i] {
+			continue
+		}
+		if after[i] != added {
+			t.Fatalf("键 %s 从 %s 迁移到了原有后端 %s", reqs[i].Header.Get("X-Key"), before[i].URL.Host, after[i].URL.Host)
+		}
+		moved++
+	}
+	if ratio := float64(moved) / float64(len(reqs)); ratio < 0.5/(n+1) || ratio > 1.5/(n+1) {
+		t.Errorf("迁移到新后端的键占比为 %.4f，期望接近 %.4f", ratio, 1.0/(n+1))
+	}
+}
+
+func TestConsistentHashBoundedLoadSpillsFromFullBackend(t *testing.T) {
+	backends := newTestBackends(t, 4)
+	ch := newTestConsistentHash(t, backends, 1.25)
+	req := keyedRequests(1)[0]
+
+	preferred := pickAll(t, ch, []*http.Request{req})[0]
+	// 首选后端的连接数达到上限 ceil((总连接数+1)/存活数×1.25) 后，键溢出到环上的下一个后端
+	for i := 0; i < 4; i++ {
+		preferred.IncrementConnections()
+	}
+	spilled := pickAll(t, ch, []*http.Request{req})[0]
+	if spilled == preferred {
+		t.Fatalf("首选后端 %s 已有%d个连接，超出负载上限后仍被选中", preferred.URL.Host, preferred.GetConnections())
+	}
+
+	// 负载回落后键回到首选后端
+	for i := 0; i < 4; i++ {
+		preferred.DecrementConnections()
+	}
+	if b := pickAll(t, ch, []*http.Request{req})[0]; b != preferred {
+		t.Errorf("负载回落后选中了 %s，期望回到首选后端 %s", b.URL.Host, preferred.URL.Host)
+	}
+}
+
+func TestConsistentHashBoundedLoadCapsHotKey(t *testing.T) {
+	const n, requests = 8, 80
+	backends := newTestBackends(t, n)
+	ch := newTestConsistentHash(t, backends, 1.25)
+	spillsBefore := counterValue(t, "go_lb_hash_spills_total", map[string]string{"algorithm": "consistent_hash"})
+
+	// 同一个热点键的并发请求：每次选中后连接数加1且不释放
+	req := keyedRequests(1)[0]
+	for i := 0; i < requests; i++ {
+		pickAll(t, ch, []*http.Request{req})[0].IncrementConnections()
+	}
+
+	// 每个后端的连接数不超过 ceil(平均连接数×负载系数)
+	limit := int64(math.Ceil(float64(requests) / n * 1.25))
+	var hottest int64
+	for _, b := range backends {
+		hottest = max(hottest, b.GetConnections())
+	}
+	if hottest > limit {
+		t.Errorf("最繁忙的后端有%d个连接，超过负载上限%d", hottest, limit)
+	}
+
+	spills := counterValue(t, "go_lb_hash_spills_total", map[string]string{"algorithm": "consistent_hash"}) - spillsBefore
+	if spills < requests-float64(limit) {
+		t.Errorf("溢出计数为%v，期望至少%d", spills, requests-limit)
+	}
+}
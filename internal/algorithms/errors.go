@@ -0,0 +1,15 @@
+package algorithms
+
+import "errors"
+
+// 定义算法选择后端时可能返回的错误
+var (
+	// ErrNoBackends 算法没有配置任何后端
+	ErrNoBackends = errors.New("没有配置后端服务器")
+
+	// ErrNoAliveBackend 所有后端均不可用
+	ErrNoAliveBackend = errors.New("没有存活的后端服务器")
+
+	// ErrNilRequest 基于请求的算法未收到请求
+	ErrNilRequest = errors.New("请求为空")
+)
@@ -1,39 +1,77 @@
 package algorithms
 
 import (
+	"context"
 	"fmt"
 	"go-load-balancer/internal/backend"
 	"net/http"
-	"strings"
 )
 
 // Algorithm 定义负载均衡算法接口
+//
+// 实现必须是并发安全的：同一个算法实例会被多个请求同时调用，
+// 与请求相关的信息只能通过Pick的参数传入，不能保存在算法实例上。
 type Algorithm interface {
-	// GetNextBackend 根据负载均衡算法获取下一个后端服务
-	GetNextBackend() *backend.Backend
+	// Pick 为指定请求选择一个后端服务，ctx为该请求的上下文
+	Pick(ctx context.Context, req *http.Request) (*backend.Backend, error)
 
 	// Name 返回算法名称
 	Name() string
+}
+
+// MembershipListener 由需要感知活跃后端变化的算法实现，
+// 健康检查将后端移入或移出活跃池后会调用UpdateBackends
+type MembershipListener interface {
+	UpdateBackends(active []*backend.Backend)
+}
+
+// ResponseHook 由需要观察后端响应的算法实现(如会话保持需要写入Cookie)，
+// 代理收到后端响应后、返回给客户端之前调用OnResponse
+type ResponseHook interface {
+	OnResponse(res *http.Response, peer *backend.Backend)
+}
 
-	// SetRequest 设置当前请求(对于基于请求的算法，如IP哈希)
-	SetRequest(req *http.Request)
-}
-
-// CreateAlgorithm 根据算法名称和后端服务列表创建对应的负载均衡算法
-func CreateAlgorithm(name string, backends []*backend.Backend) (Algorithm, error) {
-	// 转换为小写以支持大小写不敏感的配置
-	algorithmName := strings.ToLower(name)
-
-	switch algorithmName {
-	case "round_robin":
-		return NewRoundRobin(backends), nil
-	case "least_conn":
-		return NewLeastConn(backends), nil
-	case "weighted_rr":
-		return NewWeightedRoundRobin(backends), nil
-	case "ip_hash":
-		return NewIPHash(backends), nil
-	default:
+// CreateAlgorithm 根据算法名称、后端服务列表和算法参数创建对应的负载均衡算法
+func CreateAlgorithm(name string, backends []*backend.Backend, opts Options) (Algorithm, error) {
+	factory, ok := lookupFactory(name)
+	if !ok {
 		return nil, fmt.Errorf("不支持的负载均衡算法: %s", name)
 	}
+	return factory(backends, opts)
+}
+
+// noOptions 将不需要参数的算法构造函数适配为算法工厂
+func noOptions(newAlg func(backends []*backend.Backend) Algorithm) Factory {
+	return func(backends []*backend.Backend, opts Options) (Algorithm, error) {
+		// 不接受任何参数，解码到空结构体以拒绝多余的参数
+		if err := opts.Decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return newAlg(backends), nil
+	}
+}
+
+// aliveBackends 筛选出存活的后端
+func aliveBackends(backends []*backend.Backend) []*backend.Backend {
+	alive := make([]*backend.Backend, 0, len(backends))
+	for _, b := range backends {
+		if b.IsAlive() {
+			alive = append(alive, b)
+		}
+	}
+	return alive
+}
+
+// weightOf 返回后端的权重，未配置或非法的权重视为1
+func weightOf(b *backend.Backend) int {
+	if b.Weight <= 0 {
+		return 1
+	}
+	return b.Weight
+}
+
+// loadScore 计算按权重归一化的负载得分 (连接数+1)/weight，
+// 加1使空闲后端之间按权重区分
+func loadScore(b *backend.Backend, weight float64) float64 {
+	return float64(b.GetConnections()+1) / weight
 }
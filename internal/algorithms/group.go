@@ -0,0 +1,66 @@
+package algorithms
+
+import (
+	"go-load-balancer/internal/backend"
+	"net/http"
+)
+
+// backendGroup 后端的一个分组(如优先级层级、可用区)及其独立的算法实例
+type backendGroup struct {
+	name     string
+	backends []*backend.Backend
+	alg      Algorithm
+	members  map[*backend.Backend]bool
+}
+
+// newBackendGroup 创建分组，newAlg用于为分组创建算法实例
+func newBackendGroup(name string, backends []*backend.Backend, newAlg func(backends []*backend.Backend) (Algorithm, error)) (*backendGroup, error) {
+	alg, err := newAlg(backends)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[*backend.Backend]bool, len(backends))
+	for _, b := range backends {
+		members[b] = true
+	}
+	return &backendGroup{name: name, backends: backends, alg: alg, members: members}, nil
+}
+
+// capacity 返回分组的总权重和存活后端的权重
+func (g *backendGroup) capacity() (total, healthy int) {
+	for _, b := range g.backends {
+		weight := weightOf(b)
+		total += weight
+		if b.IsAlive() {
+			healthy += weight
+		}
+	}
+	return total, healthy
+}
+
+// updateBackends 将属于本分组的活跃后端转发给分组的算法
+func (g *backendGroup) updateBackends(active []*backend.Backend) {
+	l, ok := g.alg.(MembershipListener)
+	if !ok {
+		return
+	}
+	groupActive := make([]*backend.Backend, 0, len(g.backends))
+	for _, b := range active {
+		if g.members[b] {
+			groupActive = append(groupActive, b)
+		}
+	}
+	l.UpdateBackends(groupActive)
+}
+
+// onResponse 后端属于本分组时将响应转发给分组的算法，返回是否属于本分组
+func (g *backendGroup) onResponse(res *http.Response, peer *backend.Backend) bool {
+	if !g.members[peer] {
+		return false
+	}
+	if hook, ok := g.alg.(ResponseHook); ok {
+		hook.OnResponse(res, peer)
+	}
+	return true
+}
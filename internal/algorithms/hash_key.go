@@ -0,0 +1,83 @@
+package algorithms
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 哈希键的来源类型
+const (
+	HashKeyIP     = "ip"
+	HashKeyHeader = "header"
+	HashKeyCookie = "cookie"
+	HashKeyQuery  = "query"
+	HashKeyPath   = "path"
+)
+
+// HashKey 描述从请求中提取哈希键的方式
+type HashKey struct {
+	Source string // 键来源: ip, header, cookie, query, path
+	Name   string // header/cookie/query 的名称
+}
+
+// ParseHashKey 解析哈希键配置
+//
+// 支持的格式: "ip"、"path"、"header:<名称>"、"cookie:<名称>"、"query:<名称>"，
+// 空字符串等同于"ip"。
+func ParseHashKey(spec string) (HashKey, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return HashKey{Source: HashKeyIP}, nil
+	}
+
+	source, name, hasName := strings.Cut(spec, ":")
+	source = strings.ToLower(strings.TrimSpace(source))
+	name = strings.TrimSpace(name)
+
+	switch source {
+	case HashKeyIP, HashKeyPath:
+		if hasName {
+			return HashKey{}, fmt.Errorf("哈希键 %s 不需要名称: %s", source, spec)
+		}
+		return HashKey{Source: source}, nil
+	case HashKeyHeader, HashKeyCookie, HashKeyQuery:
+		if name == "" {
+			return HashKey{}, fmt.Errorf("哈希键 %s 缺少名称: %s", source, spec)
+		}
+		return HashKey{Source: source, Name: name}, nil
+	default:
+		return HashKey{}, fmt.Errorf("不支持的哈希键: %s", spec)
+	}
+}
+
+// Extract 从请求中提取哈希键，键不存在时退回到客户端IP
+func (k HashKey) Extract(req *http.Request) string {
+	var key string
+
+	switch k.Source {
+	case HashKeyHeader:
+		key = req.Header.Get(k.Name)
+	case HashKeyCookie:
+		if c, err := req.Cookie(k.Name); err == nil {
+			key = c.Value
+		}
+	case HashKeyQuery:
+		key = req.URL.Query().Get(k.Name)
+	case HashKeyPath:
+		key = req.URL.Path
+	}
+
+	if key == "" {
+		key = getClientIP(req)
+	}
+	return key
+}
+
+// String 返回哈希键的配置形式
+func (k HashKey) String() string {
+	if k.Name == "" {
+		return k.Source
+	}
+	return k.Source + ":" + k.Name
+}
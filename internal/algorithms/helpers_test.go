@@ -0,0 +1,75 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"go-load-balancer/internal/backend"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newTestBackends 创建n个权重为1的活跃后端
+func newTestBackends(t *testing.T, n int) []*backend.Backend {
+	t.Helper()
+	backends := make([]*backend.Backend, n)
+	for i := range backends {
+		b, err := backend.NewBackend(fmt.Sprintf("http://10.0.%d.%d:8080", i/250, i%250+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		backends[i] = b
+	}
+	return backends
+}
+
+// keyedRequests 创建n个带不同X-Key请求头的请求
+func keyedRequests(n int) []*http.Request {
+	reqs := make([]*http.Request, n)
+	for i := range reqs {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Key", fmt.Sprintf("key-%d", i))
+		reqs[i] = req
+	}
+	return reqs
+}
+
+// pickAll 返回每个请求选中的后端
+func pickAll(t *testing.T, alg Algorithm, reqs []*http.Request) []*backend.Backend {
+	t.Helper()
+	picked := make([]*backend.Backend, len(reqs))
+	for i, req := range reqs {
+		b, err := alg.Pick(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		picked[i] = b
+	}
+	return picked
+}
+
+// counterValue 返回默认注册表中指定名称和标签的计数器的当前值，不存在时返回0
+func counterValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range mf.GetMetric() {
+			for _, pair := range m.GetLabel() {
+				if v, ok := labels[pair.GetName()]; ok && v != pair.GetValue() {
+					continue metrics
+				}
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
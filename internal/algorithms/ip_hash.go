@@ -1,18 +1,20 @@
 package algorithms
 
 import (
+	"context"
 	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/clientip"
 	"hash/fnv"
 	"net/http"
-	"strings"
-	"sync"
 )
 
+func init() {
+	RegisterAlgorithm("ip_hash", noOptions(NewIPHash))
+}
+
 // IPHash 实现IP哈希负载均衡算法
 type IPHash struct {
 	backends []*backend.Backend
-	mu       sync.RWMutex
-	request  *http.Request // 当前请求对象
 }
 
 // NewIPHash 创建新的IP哈希算法实例
@@ -22,31 +24,24 @@ func NewIPHash(backends []*backend.Backend) Algorithm {
 	}
 }
 
-// GetNextBackend 根据IP哈希获取后端服务器
-func (ih *IPHash) GetNextBackend() *backend.Backend {
-	ih.mu.RLock()
-	defer ih.mu.RUnlock()
-
+// Pick 根据客户端IP哈希获取后端服务器
+func (ih *IPHash) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
 	// 如果没有可用后端
-	if len(ih.backends) == 0 || ih.request == nil {
-		return nil
+	if len(ih.backends) == 0 {
+		return nil, ErrNoBackends
 	}
-
-	// 筛选出活跃的后端
-	var activeBackends []*backend.Backend
-	for _, b := range ih.backends {
-		if b.IsAlive() {
-			activeBackends = append(activeBackends, b)
-		}
+	if req == nil {
+		return nil, ErrNilRequest
 	}
 
-	// 如果没有活跃的后端，返回nil
+	// 筛选出活跃的后端
+	activeBackends := aliveBackends(ih.backends)
 	if len(activeBackends) == 0 {
-		return nil
+		return nil, ErrNoAliveBackend
 	}
 
 	// 获取客户端IP
-	clientIP := ih.getClientIP()
+	clientIP := getClientIP(req)
 
 	// 计算哈希值
 	h := fnv.New32a()
@@ -56,47 +51,12 @@ func (ih *IPHash) GetNextBackend() *backend.Backend {
 	// 确定索引
 	index := int(hash % uint32(len(activeBackends)))
 
-	return activeBackends[index]
-}
-
-// 获取客户端IP地址
-func (ih *IPHash) getClientIP() string {
-	// 如果请求为空，则返回默认IP
-	if ih.request == nil {
-		return "127.0.0.1"
-	}
-
-	// 尝试从X-Forwarded-For头获取
-	ipSlice := ih.request.Header.Get("X-Forwarded-For")
-	if ipSlice != "" {
-		// X-Forwarded-For可能包含多个IP，取第一个
-		ips := strings.Split(ipSlice, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-
-	// 尝试从X-Real-IP头获取
-	ip := ih.request.Header.Get("X-Real-IP")
-	if ip != "" {
-		return ip
-	}
-
-	// 从RemoteAddr获取
-	ip = ih.request.RemoteAddr
-	// 移除端口部分
-	if i := strings.LastIndex(ip, ":"); i != -1 {
-		ip = ip[:i]
-	}
-
-	return ip
+	return activeBackends[index], nil
 }
 
-// SetRequest 设置当前请求
-func (ih *IPHash) SetRequest(req *http.Request) {
-	ih.mu.Lock()
-	defer ih.mu.Unlock()
-	ih.request = req
+// getClientIP 获取请求的客户端IP地址，由反向代理根据受信任代理配置解析
+func getClientIP(req *http.Request) string {
+	return clientip.FromRequest(req)
 }
 
 // Name 返回算法名称
@@ -0,0 +1,75 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"net/http"
+	"time"
+)
+
+func init() {
+	RegisterAlgorithm("least_time", noOptions(NewLeastTime))
+}
+
+// LeastTime 实现最短响应时间负载均衡算法
+//
+// 后端得分为 (连接数+1) × 平均首字节时间，选择得分最低的后端。
+// 尚无延迟样本的后端使用其他后端的平均值，避免新后端在第一个响应返回前吸收全部流量。
+type LeastTime struct {
+	backends []*backend.Backend
+}
+
+// NewLeastTime 创建新的最短响应时间算法实例
+func NewLeastTime(backends []*backend.Backend) Algorithm {
+	return &LeastTime{
+		backends: backends,
+	}
+}
+
+// Pick 获取得分最低的后端服务器
+func (lt *LeastTime) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	if len(lt.backends) == 0 {
+		return nil, ErrNoBackends
+	}
+
+	activeBackends := aliveBackends(lt.backends)
+	if len(activeBackends) == 0 {
+		return nil, ErrNoAliveBackend
+	}
+
+	// 计算有样本的后端的平均首字节时间，作为无样本后端的默认值
+	ttfbs := make([]time.Duration, len(activeBackends))
+	var sum time.Duration
+	var observed int
+	for i, b := range activeBackends {
+		ttfbs[i] = b.AvgTTFB()
+		if ttfbs[i] > 0 {
+			sum += ttfbs[i]
+			observed++
+		}
+	}
+	fallback := time.Duration(1)
+	if observed > 0 {
+		fallback = sum / time.Duration(observed)
+	}
+
+	var best *backend.Backend
+	var bestScore float64
+	for i, b := range activeBackends {
+		ttfb := ttfbs[i]
+		if ttfb == 0 {
+			ttfb = fallback
+		}
+		score := float64(b.GetConnections()+1) * float64(ttfb) / b.SlowStartFactor()
+		if best == nil || score < bestScore {
+			best, bestScore = b, score
+		}
+	}
+
+	return best, nil
+}
+
+// Name 返回算法名称
+func (lt *LeastTime) Name() string {
+	return "least_time"
+}
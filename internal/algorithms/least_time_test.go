@@ -0,0 +1,39 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"testing"
+	"time"
+)
+
+func TestLeastTimePrefersLowerTTFB(t *testing.T) {
+	fast, err := backend.NewBackend("http://10.0.0.1:8080", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, err := backend.NewBackend("http://10.0.0.2:8080", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast.ObserveLatency(10 * time.Millisecond)
+	slow.ObserveLatency(50 * time.Millisecond)
+
+	// slow排在前面，避免因遍历顺序碰巧选中fast
+	lt := NewLeastTime([]*backend.Backend{slow, fast})
+	for _, conns := range []int{0, 3, 10} {
+		for _, b := range []*backend.Backend{fast, slow} {
+			for b.GetConnections() < int64(conns) {
+				b.IncrementConnections()
+			}
+		}
+
+		b, err := lt.Pick(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != fast {
+			t.Errorf("连接数均为%d时选中了 %s，期望首字节时间更短的 %s", conns, b.URL.Host, fast.URL.Host)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"go-load-balancer/internal/backend"
+	"hash/fnv"
+	"net/http"
+	"sync/atomic"
+)
+
+// DefaultMaglevTableSize Maglev查找表的默认大小(质数)
+const DefaultMaglevTableSize = 65537
+
+// maglevTable 不可变的Maglev查找表
+type maglevTable struct {
+	entries []*backend.Backend
+}
+
+// MaglevOptions Maglev算法参数
+type MaglevOptions struct {
+	// HashKey 哈希键，格式同一致性哈希
+	HashKey string `mapstructure:"hash_key"`
+	// TableSize 查找表大小，必须为质数
+	TableSize int `mapstructure:"maglev_table_size"`
+}
+
+func init() {
+	RegisterAlgorithm("maglev", func(backends []*backend.Backend, opts Options) (Algorithm, error) {
+		var o MaglevOptions
+		if err := opts.Decode(&o); err != nil {
+			return nil, err
+		}
+		hashKey, err := ParseHashKey(o.HashKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewMaglev(backends, hashKey, o.TableSize)
+	})
+}
+
+// Maglev 实现Google Maglev查找表哈希算法
+//
+// 查找表由活跃后端构建，活跃池变化时整体重建并原子替换，
+// 因此查找过程无需加锁。
+type Maglev struct {
+	backends  []*backend.Backend
+	hashKey   HashKey
+	tableSize uint64
+	table     atomic.Pointer[maglevTable]
+}
+
+// NewMaglev 创建新的Maglev算法实例，tableSize为0时使用默认值
+func NewMaglev(backends []*backend.Backend, hashKey HashKey, tableSize int) (Algorithm, error) {
+	if tableSize == 0 {
+		tableSize = DefaultMaglevTableSize
+	}
+	if !isPrime(tableSize) {
+		return nil, fmt.Errorf("Maglev查找表大小必须为质数: %d", tableSize)
+	}
+
+	m := &Maglev{
+		backends:  backends,
+		hashKey:   hashKey,
+		tableSize: uint64(tableSize),
+	}
+	m.UpdateBackends(aliveBackends(backends))
+	return m, nil
+}
+
+// UpdateBackends 根据新的活跃后端重建查找表
+func (m *Maglev) UpdateBackends(active []*backend.Backend) {
+	m.table.Store(buildMaglevTable(active, m.tableSize))
+}
+
+// buildMaglevTable 按照Maglev论文中的填充过程构建查找表，
+// 每轮中每个后端按权重依次占据其排列中的下一个空位
+func buildMaglevTable(backends []*backend.Backend, size uint64) *maglevTable {
+	if len(backends) == 0 {
+		return &maglevTable{}
+	}
+
+	offsets := make([]uint64, len(backends))
+	skips := make([]uint64, len(backends))
+	next := make([]uint64, len(backends))
+	for i, b := range backends {
+		offsets[i] = maglevHash(b.URL.Host, "offset") % size
+		skips[i] = maglevHash(b.URL.Host, "skip")%(size-1) + 1
+	}
+
+	entries := make([]*backend.Backend, size)
+	filled := uint64(0)
+	for filled < size {
+		for i, b := range backends {
+			weight := weightOf(b)
+			for w := 0; w < weight && filled < size; w++ {
+				// 找到该后端排列中的下一个空位
+				c := (offsets[i] + next[i]*skips[i]) % size
+				for entries[c] != nil {
+					next[i]++
+					c = (offsets[i] + next[i]*skips[i]) % size
+				}
+				entries[c] = b
+				next[i]++
+				filled++
+			}
+		}
+	}
+
+	return &maglevTable{entries: entries}
+}
+
+// maglevHash 计算带种子的64位哈希
+func maglevHash(s, seed string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// isPrime 判断n是否为质数
+func isPrime(n int) bool {
+	if n < 2 {
+		return false
+	}
+	for i := 2; i*i <= n; i++ {
+		if n%i == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Pick 根据请求的哈希键在查找表中选择后端
+func (m *Maglev) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	if len(m.backends) == 0 {
+		return nil, ErrNoBackends
+	}
+	if req == nil {
+		return nil, ErrNilRequest
+	}
+
+	table := m.table.Load()
+	if len(table.entries) == 0 {
+		return nil, ErrNoAliveBackend
+	}
+
+	// 查找表重建之前后端可能已经不可用，此时顺序查找下一个存活的条目
+	size := uint64(len(table.entries))
+	idx := maglevHash(m.hashKey.Extract(req), "key") % size
+	for i := uint64(0); i < size; i++ {
+		b := table.entries[(idx+i)%size]
+		if b.IsAlive() {
+			return b, nil
+		}
+	}
+
+	return nil, ErrNoAliveBackend
+}
+
+// Name 返回算法名称
+func (m *Maglev) Name() string {
+	return "maglev"
+}
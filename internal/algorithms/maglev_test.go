@@ -0,0 +1,88 @@
+package algorithms
+
+import (
+	"go-load-balancer/internal/backend"
+	"math"
+	"testing"
+)
+
+// testMaglevTableSize 测试使用的查找表大小(质数)
+const testMaglevTableSize = 5003
+
+// newTestMaglev 以指定的查找表大小创建按X-Key请求头哈希的Maglev算法
+func newTestMaglev(t *testing.T, backends []*backend.Backend) *Maglev {
+	t.Helper()
+	hashKey, err := ParseHashKey("header:X-Key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alg, err := NewMaglev(backends, hashKey, testMaglevTableSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return alg.(*Maglev)
+}
+
+func TestMaglevTableBalance(t *testing.T) {
+	for _, n := range []int{3, 10, 37} {
+		m := newTestMaglev(t, newTestBackends(t, n))
+
+		entries := m.table.Load().entries
+		if len(entries) != testMaglevTableSize {
+			t.Fatalf("查找表大小为 %d，期望 %d", len(entries), testMaglevTableSize)
+		}
+		slots := make(map[*backend.Backend]int)
+		for _, b := range entries {
+			slots[b]++
+		}
+		if len(slots) != n {
+			t.Fatalf("%d个后端中只有%d个占据了查找表", n, len(slots))
+		}
+
+		lo, hi := math.MaxInt, 0
+		for _, c := range slots {
+			lo, hi = min(lo, c), max(hi, c)
+		}
+		if ratio := float64(hi) / float64(lo); ratio > 1.01 {
+			t.Errorf("n=%d: 槽位最多/最少之比为 %.3f (%d/%d)，超过1.01", n, ratio, hi, lo)
+		}
+	}
+}
+
+func TestMaglevDisruption(t *testing.T) {
+	const n = 10
+	reqs := keyedRequests(20000)
+	backends := newTestBackends(t, n+1)
+
+	m := newTestMaglev(t, backends[:n])
+	before := pickAll(t, m, reqs)
+
+	// moved 返回成员变化后改变了后端的键所占比例
+	moved := func(active []*backend.Backend) float64 {
+		m.UpdateBackends(active)
+		after := pickAll(t, m, reqs)
+		changed := 0
+		for i := range before {
+			if before[i] != after[i] {
+				changed++
+			}
+		}
+		return float64(changed) / float64(len(reqs))
+	}
+
+	tests := []struct {
+		name   string
+		active []*backend.Backend
+		ideal  float64
+	}{
+		{"移除一个后端", backends[1:n], 1.0 / n},
+		{"增加一个后端", backends, 1.0 / (n + 1)},
+	}
+	for _, tt := range tests {
+		got := moved(tt.active)
+		// Maglev在理想迁移量之外只带来少量额外迁移
+		if got < tt.ideal*0.8 || got > tt.ideal*1.5 {
+			t.Errorf("%s: 迁移比例为 %.4f，期望接近 %.4f", tt.name, got, tt.ideal)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"math/rand/v2"
+	"net/http"
+)
+
+// p2cMaxAttempts 随机抽样时最多尝试的次数，超过后退化为扫描存活后端
+const p2cMaxAttempts = 3
+
+// P2COptions 二选一算法参数
+type P2COptions struct {
+	// Weighted 是否按后端有效权重比较负载
+	Weighted bool `mapstructure:"weighted"`
+}
+
+func init() {
+	RegisterAlgorithm("p2c", func(backends []*backend.Backend, opts Options) (Algorithm, error) {
+		var o P2COptions
+		if err := opts.Decode(&o); err != nil {
+			return nil, err
+		}
+		return NewP2C(backends, o.Weighted), nil
+	})
+}
+
+// P2C 实现二选一(Power of Two Choices)负载均衡算法
+//
+// 每次随机抽取两个存活的后端，选择其中进行中请求较少的一个。
+// 算法不持有锁也不扫描全部后端，适用于后端数量很大的场景。
+type P2C struct {
+	backends []*backend.Backend
+	weighted bool // 是否按权重归一化连接数
+}
+
+// NewP2C 创建新的二选一算法实例
+func NewP2C(backends []*backend.Backend, weighted bool) Algorithm {
+	return &P2C{
+		backends: backends,
+		weighted: weighted,
+	}
+}
+
+// Pick 随机选择两个存活的后端并返回负载较低的一个
+func (p *P2C) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	return pickTwoAlive(p.backends, p.choose)
+}
+
+// pickTwoAlive 随机抽取两个存活的后端并由choose决定返回哪一个
+//
+// 多数后端存活时直接按下标抽样，不分配内存；连续抽样失败后退化为从存活后端中抽样。
+func pickTwoAlive(backends []*backend.Backend, choose func(a, b *backend.Backend) *backend.Backend) (*backend.Backend, error) {
+	n := len(backends)
+	if n == 0 {
+		return nil, ErrNoBackends
+	}
+	if n == 1 {
+		if backends[0].IsAlive() {
+			return backends[0], nil
+		}
+		return nil, ErrNoAliveBackend
+	}
+
+	// 多数后端存活时，随机抽样几乎总能直接命中
+	for attempt := 0; attempt < p2cMaxAttempts; attempt++ {
+		a, b := sampleTwo(backends)
+		aAlive, bAlive := a.IsAlive(), b.IsAlive()
+		switch {
+		case aAlive && bAlive:
+			return choose(a, b), nil
+		case aAlive:
+			return a, nil
+		case bAlive:
+			return b, nil
+		}
+	}
+
+	// 大量后端不可用时，从存活后端中抽样
+	alive := aliveBackends(backends)
+	switch len(alive) {
+	case 0:
+		return nil, ErrNoAliveBackend
+	case 1:
+		return alive[0], nil
+	}
+	a, b := sampleTwo(alive)
+	return choose(a, b), nil
+}
+
+// sampleTwo 从列表中随机抽取两个不同的后端，列表长度至少为2
+func sampleTwo(backends []*backend.Backend) (*backend.Backend, *backend.Backend) {
+	i := rand.IntN(len(backends))
+	j := rand.IntN(len(backends) - 1)
+	if j >= i {
+		j++
+	}
+	return backends[i], backends[j]
+}
+
+// choose 返回两个后端中负载较低的一个
+func (p *P2C) choose(a, b *backend.Backend) *backend.Backend {
+	if loadScore(b, p.weight(b)) < loadScore(a, p.weight(a)) {
+		return b
+	}
+	return a
+}
+
+// weight 返回比较负载时使用的权重：加权模式下为有效权重，否则为慢启动系数
+func (p *P2C) weight(b *backend.Backend) float64 {
+	if p.weighted {
+		return b.EffectiveWeight()
+	}
+	return b.SlowStartFactor()
+}
+
+// Name 返回算法名称
+func (p *P2C) Name() string {
+	return "p2c"
+}
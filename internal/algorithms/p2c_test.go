@@ -0,0 +1,71 @@
+package algorithms
+
+import (
+	"context"
+	"errors"
+	"go-load-balancer/internal/backend"
+	"testing"
+)
+
+func TestP2CPrefersLessLoadedBackend(t *testing.T) {
+	backends := newTestBackends(t, 2)
+	idle, busy := backends[0], backends[1]
+	for i := 0; i < 10; i++ {
+		busy.IncrementConnections()
+	}
+
+	// 只有两个后端时每次都会同时抽到二者，必须选中负载较低的一个
+	p := NewP2C(backends, false)
+	for i := 0; i < 100; i++ {
+		b, err := p.Pick(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != idle {
+			t.Fatalf("选中了有%d个连接的 %s，期望空闲的 %s", b.GetConnections(), b.URL.Host, idle.URL.Host)
+		}
+	}
+}
+
+func TestP2CSkipsDeadBackends(t *testing.T) {
+	backends := newTestBackends(t, 20)
+	for _, b := range backends[1:] {
+		b.SetAlive(false)
+	}
+
+	p := NewP2C(backends, false)
+	for i := 0; i < 100; i++ {
+		b, err := p.Pick(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != backends[0] {
+			t.Fatalf("选中了不可用的后端 %s", b.URL.Host)
+		}
+	}
+}
+
+func TestP2CNoBackends(t *testing.T) {
+	allDead := newTestBackends(t, 3)
+	for _, b := range allDead {
+		b.SetAlive(false)
+	}
+	singleDead := newTestBackends(t, 1)
+	singleDead[0].SetAlive(false)
+
+	tests := []struct {
+		name     string
+		backends []*backend.Backend
+		want     error
+	}{
+		{"空后端池", nil, ErrNoBackends},
+		{"全部不可用", allDead, ErrNoAliveBackend},
+		{"唯一后端不可用", singleDead, ErrNoAliveBackend},
+	}
+	for _, tt := range tests {
+		b, err := NewP2C(tt.backends, true).Pick(context.Background(), nil)
+		if !errors.Is(err, tt.want) || b != nil {
+			t.Errorf("%s: 返回 (%v, %v)，期望错误 %v", tt.name, b, err, tt.want)
+		}
+	}
+}
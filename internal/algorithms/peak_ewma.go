@@ -0,0 +1,59 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"net/http"
+	"time"
+)
+
+// peakEWMAPenalty 尚无延迟样本但已有进行中请求的后端使用的惩罚延迟，
+// 避免新后端在第一个响应返回前吸收全部流量
+const peakEWMAPenalty = time.Second
+
+func init() {
+	RegisterAlgorithm("peak_ewma", noOptions(NewPeakEWMA))
+}
+
+// PeakEWMA 实现基于峰值敏感EWMA延迟的负载均衡算法
+//
+// 后端得分为 EWMA延迟 × (进行中请求数+1)，每次随机抽取两个存活后端并选择得分较低的一个。
+// 后端变慢(如GC停顿、邻居干扰)时得分立即升高，无需等待健康检查失败即可自动减少流量。
+type PeakEWMA struct {
+	backends []*backend.Backend
+}
+
+// NewPeakEWMA 创建新的Peak-EWMA算法实例
+func NewPeakEWMA(backends []*backend.Backend) Algorithm {
+	return &PeakEWMA{
+		backends: backends,
+	}
+}
+
+// Pick 随机选择两个存活的后端并返回得分较低的一个
+func (pe *PeakEWMA) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	return pickTwoAlive(pe.backends, pe.choose)
+}
+
+// choose 返回两个后端中得分较低的一个
+func (pe *PeakEWMA) choose(a, b *backend.Backend) *backend.Backend {
+	if pe.score(b) < pe.score(a) {
+		return b
+	}
+	return a
+}
+
+// score 计算后端的负载得分
+func (pe *PeakEWMA) score(b *backend.Backend) float64 {
+	inflight := b.GetConnections()
+	latency := b.PeakEWMA()
+	if latency == 0 && inflight > 0 {
+		latency = peakEWMAPenalty
+	}
+	return float64(latency) * float64(inflight+1) / b.SlowStartFactor()
+}
+
+// Name 返回算法名称
+func (pe *PeakEWMA) Name() string {
+	return "peak_ewma"
+}
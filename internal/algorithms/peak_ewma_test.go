@@ -0,0 +1,67 @@
+package algorithms
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPeakEWMAAvoidsSlowBackend(t *testing.T) {
+	backends := newTestBackends(t, 2)
+	fast, slow := backends[0], backends[1]
+	fast.ObserveLatency(10 * time.Millisecond)
+	slow.ObserveLatency(5 * time.Second)
+
+	pe := NewPeakEWMA(backends)
+	for i := 0; i < 100; i++ {
+		b, err := pe.Pick(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != fast {
+			t.Fatalf("选中了延迟较高的 %s", b.URL.Host)
+		}
+	}
+}
+
+func TestPeakEWMAPenalizesInflightWithoutSamples(t *testing.T) {
+	backends := newTestBackends(t, 2)
+	idle, busy := backends[0], backends[1]
+	idle.ObserveLatency(100 * time.Millisecond)
+	// 尚无延迟样本的新后端在有进行中请求时按惩罚延迟计分
+	busy.IncrementConnections()
+
+	pe := NewPeakEWMA(backends)
+	for i := 0; i < 100; i++ {
+		b, err := pe.Pick(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != idle {
+			t.Fatalf("选中了无延迟样本但有进行中请求的 %s", b.URL.Host)
+		}
+	}
+}
+
+func TestPeakEWMASkipsDeadBackends(t *testing.T) {
+	backends := newTestBackends(t, 10)
+	for _, b := range backends[:9] {
+		b.SetAlive(false)
+	}
+
+	pe := NewPeakEWMA(backends)
+	for i := 0; i < 50; i++ {
+		b, err := pe.Pick(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != backends[9] {
+			t.Fatalf("选中了不可用的后端 %s", b.URL.Host)
+		}
+	}
+
+	backends[9].SetAlive(false)
+	if _, err := pe.Pick(context.Background(), nil); err != ErrNoAliveBackend {
+		t.Fatalf("全部不可用时返回 %v，期望 %v", err, ErrNoAliveBackend)
+	}
+}
@@ -0,0 +1,101 @@
+package algorithms
+
+import (
+	"fmt"
+	"go-load-balancer/internal/backend"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// Factory 算法工厂，根据后端列表和算法参数创建算法实例
+//
+// 工厂也用于配置校验，此时backends为空，工厂应只检查参数并返回参数错误。
+type Factory func(backends []*backend.Backend, opts Options) (Algorithm, error)
+
+// Options 配置文件algorithm_options中的原始算法参数，
+// 各算法通过Decode解码为自己的参数结构体
+type Options map[string]interface{}
+
+// Decode 将原始参数解码到out指向的结构体，字段通过mapstructure标签匹配，
+// 包含不属于该结构体的参数时返回错误，使配置中拼错或不适用于所选算法的参数在校验时被发现
+func (o Options) Decode(out interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           out,
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(map[string]interface{}(o)); err != nil {
+		return fmt.Errorf("解析算法参数失败: %v", err)
+	}
+	return nil
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// RegisterAlgorithm 注册负载均衡算法，名称大小写不敏感
+//
+// 通常在算法所在包的init函数中调用，使自定义算法无需修改本仓库即可通过配置选用。
+// 名称为空、工厂为nil或重复注册时panic。
+func RegisterAlgorithm(name string, factory Factory) {
+	name = strings.ToLower(name)
+	if name == "" {
+		panic("algorithms: 算法名称不能为空")
+	}
+	if factory == nil {
+		panic("algorithms: 算法工厂不能为nil: " + name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic("algorithms: 重复注册算法: " + name)
+	}
+	registry[name] = factory
+}
+
+// lookupFactory 查找已注册的算法工厂
+func lookupFactory(name string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[strings.ToLower(name)]
+	return factory, ok
+}
+
+// IsRegistered 判断算法是否已注册
+func IsRegistered(name string) bool {
+	_, ok := lookupFactory(name)
+	return ok
+}
+
+// RegisteredAlgorithms 返回所有已注册算法的名称(按字母排序)
+func RegisteredAlgorithms() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateOptions 检查算法是否已注册以及算法参数是否有效
+func ValidateOptions(name string, opts Options) error {
+	factory, ok := lookupFactory(name)
+	if !ok {
+		return fmt.Errorf("不支持的负载均衡算法: %s (可选: %s)", name, strings.Join(RegisteredAlgorithms(), ", "))
+	}
+	_, err := factory(nil, opts)
+	return err
+}
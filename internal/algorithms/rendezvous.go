@@ -0,0 +1,133 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"sort"
+)
+
+// RendezvousOptions HRW哈希算法参数
+type RendezvousOptions struct {
+	// HashKey 哈希键，格式同一致性哈希
+	HashKey string `mapstructure:"hash_key"`
+}
+
+func init() {
+	RegisterAlgorithm("rendezvous", func(backends []*backend.Backend, opts Options) (Algorithm, error) {
+		var o RendezvousOptions
+		if err := opts.Decode(&o); err != nil {
+			return nil, err
+		}
+		hashKey, err := ParseHashKey(o.HashKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewRendezvous(backends, hashKey), nil
+	})
+}
+
+// Rendezvous 实现加权最高随机权重(HRW)哈希负载均衡算法
+//
+// 对每个后端计算 score = -weight / ln(u)，其中u为键与后端组合哈希映射到(0,1)的值，
+// 选择得分最高的后端。对数加权法使每个后端被选中的概率严格等于其权重占比，
+// 且不需要维护哈希环等状态。
+type Rendezvous struct {
+	backends []*backend.Backend
+	seeds    []uint64 // 每个后端的哈希种子
+	hashKey  HashKey
+}
+
+// NewRendezvous 创建新的HRW哈希算法实例
+func NewRendezvous(backends []*backend.Backend, hashKey HashKey) Algorithm {
+	seeds := make([]uint64, len(backends))
+	for i, b := range backends {
+		h := fnv.New64a()
+		h.Write([]byte(b.URL.Host))
+		seeds[i] = h.Sum64()
+	}
+	return &Rendezvous{
+		backends: backends,
+		seeds:    seeds,
+		hashKey:  hashKey,
+	}
+}
+
+// Pick 返回请求哈希键得分最高的存活后端
+func (r *Rendezvous) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	if len(r.backends) == 0 {
+		return nil, ErrNoBackends
+	}
+	if req == nil {
+		return nil, ErrNilRequest
+	}
+
+	keyHash := hashString(r.hashKey.Extract(req))
+	var best *backend.Backend
+	bestScore := math.Inf(-1)
+	for i, b := range r.backends {
+		if !b.IsAlive() {
+			continue
+		}
+		if score := r.score(keyHash, i); score > bestScore {
+			best, bestScore = b, score
+		}
+	}
+
+	if best == nil {
+		return nil, ErrNoAliveBackend
+	}
+	return best, nil
+}
+
+// rankAll 返回按键得分从高到低排列的全部后端，不考虑存活状态
+func (r *Rendezvous) rankAll(key string) []*backend.Backend {
+	keyHash := hashString(key)
+	scores := make([]float64, len(r.backends))
+	idx := make([]int, len(r.backends))
+	for i := range r.backends {
+		scores[i] = r.score(keyHash, i)
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool {
+		return scores[idx[i]] > scores[idx[j]]
+	})
+
+	ranked := make([]*backend.Backend, len(idx))
+	for i, j := range idx {
+		ranked[i] = r.backends[j]
+	}
+	return ranked
+}
+
+// score 计算键与第i个后端组合的加权得分
+func (r *Rendezvous) score(keyHash uint64, i int) float64 {
+	// 取53位映射到(0,1)开区间，保证ln(u)为有限负数
+	h := mix64(keyHash ^ r.seeds[i])
+	u := (float64(h>>11) + 0.5) / (1 << 53)
+	return -float64(weightOf(r.backends[i])) / math.Log(u)
+}
+
+// hashString 计算字符串的64位哈希
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix64 splitmix64终结函数，使相近输入的哈希充分扩散
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Name 返回算法名称
+func (r *Rendezvous) Name() string {
+	return "rendezvous"
+}
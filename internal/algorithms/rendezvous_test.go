@@ -0,0 +1,84 @@
+package algorithms
+
+import (
+	"go-load-balancer/internal/backend"
+	"math"
+	"testing"
+)
+
+// newTestRendezvous 创建按X-Key请求头哈希的HRW哈希算法
+func newTestRendezvous(t *testing.T, backends []*backend.Backend) Algorithm {
+	t.Helper()
+	hashKey, err := ParseHashKey("header:X-Key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRendezvous(backends, hashKey)
+}
+
+func TestRendezvousWeightProportional(t *testing.T) {
+	backends := newTestBackends(t, 4)
+	totalWeight := 0
+	for i, b := range backends {
+		b.Weight = i + 1
+		totalWeight += b.Weight
+	}
+
+	reqs := keyedRequests(50000)
+	counts := make(map[*backend.Backend]int)
+	for _, b := range pickAll(t, newTestRendezvous(t, backends), reqs) {
+		counts[b]++
+	}
+
+	for _, b := range backends {
+		want := float64(b.Weight) / float64(totalWeight)
+		got := float64(counts[b]) / float64(len(reqs))
+		if math.Abs(got-want) > want*0.05 {
+			t.Errorf("权重为%d的后端 %s 选中占比为 %.4f，期望接近 %.4f", b.Weight, b.URL.Host, got, want)
+		}
+	}
+}
+
+func TestRendezvousRemoveRemapsOnlyRemovedKeys(t *testing.T) {
+	const n = 10
+	reqs := keyedRequests(20000)
+	backends := newTestBackends(t, n)
+	before := pickAll(t, newTestRendezvous(t, backends), reqs)
+
+	// 从后端列表中移除一个节点，其余节点的哈希种子不变
+	removed := backends[3]
+	remaining := append(append([]*backend.Backend{}, backends[:3]...), backends[4:]...)
+	after := pickAll(t, newTestRendezvous(t, remaining), reqs)
+
+	moved := 0
+	for i := range reqs {
+		switch {
+		case before[i] == removed:
+			moved++
+		case after[i] != before[i]:
+			t.Fatalf("键 %s 原本不在移除的后端上，却从 %s 迁移到了 %s",
+				reqs[i].Header.Get("X-Key"), before[i].URL.Host, after[i].URL.Host)
+		}
+	}
+	if ratio := float64(moved) / float64(len(reqs)); ratio < 0.5/n || ratio > 1.5/n {
+		t.Errorf("移除后端上的键占比为 %.4f，期望接近 %.4f", ratio, 1.0/n)
+	}
+}
+
+func TestRendezvousDeadBackendRemapsOnlyItsKeys(t *testing.T) {
+	reqs := keyedRequests(5000)
+	backends := newTestBackends(t, 5)
+	r := newTestRendezvous(t, backends)
+	before := pickAll(t, r, reqs)
+
+	backends[1].SetAlive(false)
+	after := pickAll(t, r, reqs)
+	for i := range reqs {
+		if after[i] == backends[1] {
+			t.Fatal("键仍然映射到不可用的后端")
+		}
+		if before[i] != backends[1] && after[i] != before[i] {
+			t.Fatalf("键 %s 原本不在不可用的后端上，却发生了迁移", reqs[i].Header.Get("X-Key"))
+		}
+	}
+}
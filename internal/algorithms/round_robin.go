@@ -1,11 +1,17 @@
 package algorithms
 
 import (
+	"context"
 	"go-load-balancer/internal/backend"
+	"math/rand/v2"
 	"net/http"
 	"sync/atomic"
 )
 
+func init() {
+	RegisterAlgorithm("round_robin", noOptions(NewRoundRobin))
+}
+
 // RoundRobin 实现轮询负载均衡算法
 type RoundRobin struct {
 	backends []*backend.Backend
@@ -20,29 +26,40 @@ func NewRoundRobin(backends []*backend.Backend) Algorithm {
 	}
 }
 
-// GetNextBackend 获取下一个后端服务器
-func (r *RoundRobin) GetNextBackend() *backend.Backend {
+// Pick 获取下一个存活的后端服务器
+func (r *RoundRobin) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
 	// 如果没有可用后端
 	if len(r.backends) == 0 {
-		return nil
+		return nil, ErrNoBackends
 	}
 
-	// 获取当前索引并原子递增
+	// 从原子递增的位置开始，最多轮询一圈寻找存活的后端
+	n := uint64(len(r.backends))
 	next := atomic.AddUint64(&r.current, 1)
+	var fallback *backend.Backend
+	for i := uint64(0); i < n; i++ {
+		b := r.backends[(next+i)%n]
+		if !b.IsAlive() {
+			continue
+		}
+		// 慢启动期间的后端按慢启动系数的概率被选中
+		if factor := b.SlowStartFactor(); factor < 1 && rand.Float64() >= factor {
+			if fallback == nil {
+				fallback = b
+			}
+			continue
+		}
+		return b, nil
+	}
 
-	// 计算实际索引
-	index := int(next % uint64(len(r.backends)))
-
-	// 返回对应的后端
-	return r.backends[index]
+	// 所有存活后端都在慢启动期间且本轮未被选中
+	if fallback != nil {
+		return fallback, nil
+	}
+	return nil, ErrNoAliveBackend
 }
 
 // Name 返回算法名称
 func (r *RoundRobin) Name() string {
 	return "round_robin"
 }
-
-// SetRequest 设置当前请求(轮询算法不需要请求信息)
-func (r *RoundRobin) SetRequest(req *http.Request) {
-	// 轮询算法不依赖请求信息，此处为接口实现的空方法
-}
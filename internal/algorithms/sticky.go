@@ -0,0 +1,370 @@
+package algorithms
+
+import (
+	"container/list"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-load-balancer/internal/backend"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 会话保持模式
+const (
+	// StickyModeLB 由负载均衡器签发记录后端的签名Cookie
+	StickyModeLB = "lb"
+	// StickyModeApp 跟随应用签发的会话Cookie(如JSESSIONID)，记录会话与后端的对应关系
+	StickyModeApp = "app"
+)
+
+// DefaultStickyCookieName 负载均衡器签发的会话Cookie默认名称
+const DefaultStickyCookieName = "lb_sticky"
+
+// DefaultStickyAppIdleTTL app模式下未配置TTL时会话的默认空闲过期时间
+const DefaultStickyAppIdleTTL = 30 * time.Minute
+
+// DefaultStickyMaxSessions app模式下会话表的默认最大条目数
+const DefaultStickyMaxSessions = 100000
+
+// StickyOptions 会话保持参数
+type StickyOptions struct {
+	Mode       string        // lb 或 app
+	CookieName string        // lb模式下签发的Cookie名称，app模式下为应用的会话Cookie名称
+	TTL        time.Duration // 会话有效期，0表示浏览器会话期间有效；app模式下为空闲过期时间，0时使用默认值
+	SameSite   http.SameSite
+	Secure     bool
+	HTTPOnly   bool
+	Secret     string // Cookie签名密钥，为空时启动时随机生成
+	// MaxSessions app模式下会话表的最大条目数，超出时淘汰最久未使用的会话，不大于0时使用默认值
+	MaxSessions int
+}
+
+// appSession 应用会话对应的后端
+type appSession struct {
+	value   string
+	backend *backend.Backend
+	expires time.Time
+}
+
+// appSessionTable app模式下会话值到后端的LRU映射，切换算法时可在新旧实例间共享
+//
+// 每次命中都会刷新会话的过期时间并移到链表头部，因此链表从尾到头按过期时间排列，
+// 清理时只需从尾部移除已过期的会话。
+type appSessionTable struct {
+	mu       sync.Mutex
+	sessions map[string]*list.Element // 元素值为*appSession
+	lru      *list.List
+	idleTTL  time.Duration
+	max      int
+}
+
+// newAppSessionTable 创建应用会话表
+func newAppSessionTable(idleTTL time.Duration, max int) *appSessionTable {
+	if idleTTL <= 0 {
+		idleTTL = DefaultStickyAppIdleTTL
+	}
+	if max <= 0 {
+		max = DefaultStickyMaxSessions
+	}
+	return &appSessionTable{
+		sessions: make(map[string]*list.Element),
+		lru:      list.New(),
+		idleTTL:  idleTTL,
+		max:      max,
+	}
+}
+
+// get 返回会话对应的后端并刷新其过期时间，会话不存在或已过期时返回nil
+func (t *appSessionTable) get(value string, now time.Time) *backend.Backend {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sweepLocked(now)
+	e, ok := t.sessions[value]
+	if !ok {
+		return nil
+	}
+	session := e.Value.(*appSession)
+	session.expires = now.Add(t.idleTTL)
+	t.lru.MoveToFront(e)
+	return session.backend
+}
+
+// put 记录会话对应的后端，超出容量时淘汰最久未使用的会话
+func (t *appSessionTable) put(value string, b *backend.Backend, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sweepLocked(now)
+	if e, ok := t.sessions[value]; ok {
+		session := e.Value.(*appSession)
+		session.backend = b
+		session.expires = now.Add(t.idleTTL)
+		t.lru.MoveToFront(e)
+		return
+	}
+
+	t.sessions[value] = t.lru.PushFront(&appSession{value: value, backend: b, expires: now.Add(t.idleTTL)})
+	for t.lru.Len() > t.max {
+		t.removeLocked(t.lru.Back())
+	}
+}
+
+// remove 删除会话
+func (t *appSessionTable) remove(value string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e, ok := t.sessions[value]; ok {
+		t.removeLocked(e)
+	}
+}
+
+// sweepLocked 从链表尾部清理过期的会话，调用方需持有锁
+func (t *appSessionTable) sweepLocked(now time.Time) {
+	for e := t.lru.Back(); e != nil && now.After(e.Value.(*appSession).expires); e = t.lru.Back() {
+		t.removeLocked(e)
+	}
+}
+
+// removeLocked 删除链表元素及其索引，调用方需持有锁
+func (t *appSessionTable) removeLocked(e *list.Element) {
+	t.lru.Remove(e)
+	delete(t.sessions, e.Value.(*appSession).value)
+}
+
+// Sticky 为任意负载均衡算法添加基于Cookie的会话保持
+//
+// 请求携带的Cookie指向的后端存活时直接使用该后端，否则交给被包装的算法选择。
+type Sticky struct {
+	inner  Algorithm
+	opts   StickyOptions
+	secret []byte
+	byID   map[string]*backend.Backend
+	ids    map[*backend.Backend]string
+	app    *appSessionTable
+}
+
+// NewSticky 使用会话保持包装算法
+func NewSticky(inner Algorithm, backends []*backend.Backend, opts StickyOptions) (Algorithm, error) {
+	switch opts.Mode {
+	case "":
+		opts.Mode = StickyModeLB
+	case StickyModeLB, StickyModeApp:
+	default:
+		return nil, fmt.Errorf("不支持的会话保持模式: %s", opts.Mode)
+	}
+	if opts.CookieName == "" {
+		if opts.Mode == StickyModeApp {
+			return nil, errors.New("app会话保持模式需要指定应用Cookie名称")
+		}
+		opts.CookieName = DefaultStickyCookieName
+	}
+
+	secret := []byte(opts.Secret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("生成会话签名密钥失败: %v", err)
+		}
+	}
+
+	s := &Sticky{
+		inner:  inner,
+		opts:   opts,
+		secret: secret,
+		byID:   make(map[string]*backend.Backend, len(backends)),
+		ids:    make(map[*backend.Backend]string, len(backends)),
+		app:    newAppSessionTable(opts.TTL, opts.MaxSessions),
+	}
+	for _, b := range backends {
+		id := backendID(b)
+		s.byID[id] = b
+		s.ids[b] = id
+	}
+	return s, nil
+}
+
+// NewStickyFrom 与NewSticky相同，但会话保持参数与prev一致时沿用prev的签名密钥和应用会话，
+// 使运行时切换被包装的算法后已有会话继续有效
+func NewStickyFrom(prev Algorithm, inner Algorithm, backends []*backend.Backend, opts StickyOptions) (Algorithm, error) {
+	alg, err := NewSticky(inner, backends, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s := alg.(*Sticky)
+	if p, ok := prev.(*Sticky); ok && p.opts == s.opts {
+		s.secret = p.secret
+		s.app = p.app
+	}
+	return s, nil
+}
+
+// backendID 生成后端的稳定标识，避免在Cookie中暴露后端地址
+func backendID(b *backend.Backend) string {
+	sum := sha256.Sum256([]byte(b.URL.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Pick 优先返回会话对应的存活后端，否则由被包装的算法选择
+func (s *Sticky) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	if req != nil {
+		if b := s.lookup(req); b != nil && b.IsAlive() {
+			return b, nil
+		}
+	}
+	return s.inner.Pick(ctx, req)
+}
+
+// lookup 根据请求中的Cookie查找会话对应的后端
+func (s *Sticky) lookup(req *http.Request) *backend.Backend {
+	c, err := req.Cookie(s.opts.CookieName)
+	if err != nil || c.Value == "" {
+		return nil
+	}
+
+	if s.opts.Mode == StickyModeApp {
+		return s.app.get(c.Value, time.Now())
+	}
+
+	id, ok := s.verify(c.Value)
+	if !ok {
+		return nil
+	}
+	return s.byID[id]
+}
+
+// OnResponse 在lb模式下为新会话写入签名Cookie，在app模式下记录应用签发的会话
+func (s *Sticky) OnResponse(res *http.Response, peer *backend.Backend) {
+	if hook, ok := s.inner.(ResponseHook); ok {
+		hook.OnResponse(res, peer)
+	}
+
+	if s.opts.Mode == StickyModeApp {
+		s.learn(res, peer)
+		return
+	}
+
+	// 请求已携带指向该后端的有效Cookie时无需重复签发
+	if res.Request != nil {
+		if c, err := res.Request.Cookie(s.opts.CookieName); err == nil {
+			if id, ok := s.verify(c.Value); ok && s.byID[id] == peer {
+				return
+			}
+		}
+	}
+
+	id, ok := s.ids[peer]
+	if !ok {
+		return
+	}
+	cookie := &http.Cookie{
+		Name:     s.opts.CookieName,
+		Value:    s.sign(id, s.expiry()),
+		Path:     "/",
+		SameSite: s.opts.SameSite,
+		Secure:   s.opts.Secure,
+		HttpOnly: s.opts.HTTPOnly,
+	}
+	if s.opts.TTL > 0 {
+		cookie.MaxAge = int(s.opts.TTL.Seconds())
+	}
+	res.Header.Add("Set-Cookie", cookie.String())
+}
+
+// learn 记录后端响应中签发的应用会话Cookie
+func (s *Sticky) learn(res *http.Response, peer *backend.Backend) {
+	for _, c := range res.Cookies() {
+		if c.Name != s.opts.CookieName {
+			continue
+		}
+
+		if c.Value == "" || c.MaxAge < 0 {
+			// 应用删除了会话
+			for _, reqCookie := range requestCookies(res.Request, s.opts.CookieName) {
+				s.app.remove(reqCookie.Value)
+			}
+		} else {
+			s.app.put(c.Value, peer, time.Now())
+		}
+	}
+}
+
+// requestCookies 返回请求中指定名称的Cookie
+func requestCookies(req *http.Request, name string) []*http.Cookie {
+	if req == nil {
+		return nil
+	}
+	return req.CookiesNamed(name)
+}
+
+// expiry 返回lb模式下新Cookie的过期时间，TTL为0时返回零值
+func (s *Sticky) expiry() time.Time {
+	if s.opts.TTL <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(s.opts.TTL)
+}
+
+// sign 生成Cookie值: <后端标识>.<过期时间戳>.<签名>
+func (s *Sticky) sign(id string, expires time.Time) string {
+	var exp int64
+	if !expires.IsZero() {
+		exp = expires.Unix()
+	}
+	payload := id + "." + strconv.FormatInt(exp, 10)
+	return payload + "." + s.mac(payload)
+}
+
+// verify 校验Cookie签名和有效期，返回其中的后端标识
+func (s *Sticky) verify(value string) (string, bool) {
+	i := strings.LastIndexByte(value, '.')
+	if i < 0 {
+		return "", false
+	}
+	payload, sig := value[:i], value[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(s.mac(payload))) {
+		return "", false
+	}
+
+	id, expStr, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", false
+	}
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	if exp > 0 && time.Now().Unix() > exp {
+		return "", false
+	}
+	return id, true
+}
+
+// mac 计算载荷的HMAC签名
+func (s *Sticky) mac(payload string) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// UpdateBackends 将活跃后端变化转发给被包装的算法
+func (s *Sticky) UpdateBackends(active []*backend.Backend) {
+	if l, ok := s.inner.(MembershipListener); ok {
+		l.UpdateBackends(active)
+	}
+}
+
+// Name 返回算法名称
+func (s *Sticky) Name() string {
+	return s.inner.Name() + "+sticky"
+}
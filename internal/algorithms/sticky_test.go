@@ -0,0 +1,61 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAppSessionTableEvictsLeastRecentlyUsed(t *testing.T) {
+	backends := newTestBackends(t, 1)
+	table := newAppSessionTable(time.Hour, 3)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		table.put(fmt.Sprintf("s%d", i), backends[0], now)
+	}
+	// 访问s0后，最久未使用的会话变为s1
+	if table.get("s0", now) == nil {
+		t.Fatal("会话s0丢失")
+	}
+	table.put("s3", backends[0], now)
+
+	if n := table.lru.Len(); n != 3 {
+		t.Fatalf("会话数为%d，期望不超过上限3", n)
+	}
+	if table.get("s1", now) != nil {
+		t.Error("最久未使用的会话s1未被淘汰")
+	}
+	for _, v := range []string{"s0", "s2", "s3"} {
+		if table.get(v, now) == nil {
+			t.Errorf("会话%s被错误淘汰", v)
+		}
+	}
+}
+
+func TestAppSessionTableIdleExpiry(t *testing.T) {
+	backends := newTestBackends(t, 1)
+	table := newAppSessionTable(0, 0)
+	if table.idleTTL != DefaultStickyAppIdleTTL || table.max != DefaultStickyMaxSessions {
+		t.Fatalf("默认值为 %v/%d，期望 %v/%d", table.idleTTL, table.max, DefaultStickyAppIdleTTL, DefaultStickyMaxSessions)
+	}
+
+	now := time.Now()
+	table.put("active", backends[0], now)
+	table.put("idle", backends[0], now)
+
+	// 命中会刷新空闲计时
+	half := now.Add(DefaultStickyAppIdleTTL / 2)
+	table.get("active", half)
+
+	later := now.Add(DefaultStickyAppIdleTTL + time.Second)
+	if table.get("idle", later) != nil {
+		t.Error("空闲超时的会话未过期")
+	}
+	if table.get("active", later) == nil {
+		t.Error("最近命中的会话被错误清理")
+	}
+	if n := table.lru.Len(); n != 1 {
+		t.Errorf("清理后会话数为%d，期望1", n)
+	}
+}
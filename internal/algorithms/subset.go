@@ -0,0 +1,326 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"go-load-balancer/internal/backend"
+	"log"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// 子集划分方式
+const (
+	// SubsetAperture Google SRE确定性子集划分：各实例在首尾相接的各轮随机排列中依次取连续的一段，
+	// 各后端被使用的实例数最多相差1
+	SubsetAperture = "aperture"
+	// SubsetRendezvous 以实例编号为键的HRW哈希取得分最高的若干后端，成员变化时迁移最少，
+	// 但各后端被使用的实例数只是统计意义上均匀
+	SubsetRendezvous = "rendezvous"
+)
+
+// DefaultSubsetSize 未配置子集大小时每个实例连接的后端数上限
+const DefaultSubsetSize = 10
+
+// SubsetOptions 确定性子集划分参数
+type SubsetOptions struct {
+	InstanceID    int    // 本实例编号，取值[0, InstanceCount)
+	InstanceCount int    // 负载均衡器实例总数
+	Size          int    // 每个实例使用的后端数，0表示使用默认值
+	Method        string // aperture(默认) 或 rendezvous
+}
+
+// Subset 只在本实例的后端子集上运行内部算法
+//
+// 每个负载均衡器实例根据实例编号确定性地选出后端子集，使实例与后端之间的连接数
+// 从 实例数×后端数 降为 实例数×子集大小，同时各后端被大致相同数量的实例使用。
+// 子集大小不小于 ceil(后端数/实例数)，保证每个后端至少被一个实例使用。
+// 活跃后端变化时重新计算子集，子集变化后用newAlg重建内部算法。
+type Subset struct {
+	backends []*backend.Backend
+	all      map[*backend.Backend]bool
+	opts     SubsetOptions
+	newAlg   func(backends []*backend.Backend) (Algorithm, error)
+	ranker   *Rendezvous
+
+	mu      sync.Mutex // 串行化子集重建
+	members []*backend.Backend
+	inner   atomic.Pointer[subsetState]
+}
+
+// subsetState 当前子集及其算法实例
+type subsetState struct {
+	members map[*backend.Backend]bool
+	alg     Algorithm
+}
+
+// NewSubset 创建子集划分算法，newAlg用于为子集创建算法实例
+func NewSubset(backends []*backend.Backend, opts SubsetOptions, newAlg func(backends []*backend.Backend) (Algorithm, error)) (Algorithm, error) {
+	if opts.InstanceCount <= 0 {
+		return nil, fmt.Errorf("负载均衡器实例总数必须大于0: %d", opts.InstanceCount)
+	}
+	if opts.InstanceID < 0 || opts.InstanceID >= opts.InstanceCount {
+		return nil, fmt.Errorf("实例编号必须在[0, %d)之间: %d", opts.InstanceCount, opts.InstanceID)
+	}
+	switch opts.Method {
+	case "":
+		opts.Method = SubsetAperture
+	case SubsetAperture, SubsetRendezvous:
+	default:
+		return nil, fmt.Errorf("不支持的子集划分方式: %s", opts.Method)
+	}
+
+	s := &Subset{
+		backends: backends,
+		all:      make(map[*backend.Backend]bool, len(backends)),
+		opts:     opts,
+		newAlg:   newAlg,
+	}
+	for _, b := range backends {
+		s.all[b] = true
+	}
+	if opts.Method == SubsetRendezvous {
+		s.ranker = NewRendezvous(backends, HashKey{Source: HashKeyIP}).(*Rendezvous)
+	}
+
+	if err := s.rebuild(aliveBackends(backends)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Pick 使用子集的算法选择后端
+func (s *Subset) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	if len(s.backends) == 0 {
+		return nil, ErrNoBackends
+	}
+	return s.inner.Load().alg.Pick(ctx, req)
+}
+
+// UpdateBackends 根据新的活跃后端重新计算子集，子集不变时将变化转发给子集的算法
+func (s *Subset) UpdateBackends(active []*backend.Backend) {
+	mine := make([]*backend.Backend, 0, len(active))
+	for _, b := range active {
+		if s.all[b] {
+			mine = append(mine, b)
+		}
+	}
+
+	if err := s.rebuild(mine); err != nil {
+		log.Printf("重建后端子集失败: %v", err)
+	}
+}
+
+// rebuild 根据活跃后端计算子集，子集变化时重建内部算法
+func (s *Subset) rebuild(active []*backend.Backend) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members := s.selectMembers(active)
+	state := s.inner.Load()
+	if state != nil && sameMembers(state.members, members) {
+		if l, ok := state.alg.(MembershipListener); ok {
+			l.UpdateBackends(members)
+		}
+		return nil
+	}
+
+	alg, err := s.newAlg(members)
+	if err != nil {
+		return err
+	}
+	set := make(map[*backend.Backend]bool, len(members))
+	for _, b := range members {
+		set[b] = true
+	}
+	s.inner.Store(&subsetState{members: set, alg: alg})
+	s.members = members
+
+	if state != nil {
+		log.Printf("后端子集已更新: %d/%d 个后端", len(members), len(s.backends))
+	}
+	return nil
+}
+
+// selectMembers 从活跃后端中选出本实例的子集，没有活跃后端时保留当前子集
+func (s *Subset) selectMembers(active []*backend.Backend) []*backend.Backend {
+	if len(active) == 0 {
+		if s.members != nil {
+			return s.members
+		}
+		active = s.backends
+	}
+	if len(active) == 0 {
+		return nil
+	}
+
+	size := s.subsetSize(len(active))
+	if s.opts.Method == SubsetAperture {
+		return s.aperture(active, size)
+	}
+	return s.rendezvous(active, size)
+}
+
+// subsetSize 计算子集大小：不小于 ceil(n/实例数)，不大于n
+func (s *Subset) subsetSize(n int) int {
+	size := s.opts.Size
+	if size <= 0 {
+		size = DefaultSubsetSize
+	}
+	if minSize := (n + s.opts.InstanceCount - 1) / s.opts.InstanceCount; size < minSize {
+		size = minSize
+	}
+	if size > n {
+		size = n
+	}
+	return size
+}
+
+// aperture Google SRE确定性子集划分
+//
+// 以轮次为种子对全部配置的后端做随机排列，各轮排列首尾相接组成一个序列，
+// 第i个实例取序列中从 i×子集大小 开始的一段，跳过不活跃的后端并向后补足。
+// 所有实例的分段连续覆盖序列的前 实例数×子集大小 个位置，因此每个后端都会被使用，
+// 且各后端被使用的实例数最多相差1。排列和分段基于全部配置的后端，
+// 个别后端上下线只会使相关分段向后多取少量位置。
+func (s *Subset) aperture(active []*backend.Backend, size int) []*backend.Backend {
+	isActive := make(map[*backend.Backend]bool, len(active))
+	for _, b := range active {
+		isActive[b] = true
+	}
+
+	// 分段位置按全部配置的后端计算，不随健康状态变化
+	seq := newApertureSequence(s.backends, s.subsetSize(len(s.backends)))
+	members := make([]*backend.Backend, 0, size)
+	seen := make(map[*backend.Backend]bool, size)
+	for pos := s.opts.InstanceID * seq.window; len(members) < size; pos++ {
+		b := seq.at(pos)
+		if isActive[b] && !seen[b] {
+			seen[b] = true
+			members = append(members, b)
+		}
+	}
+	return members
+}
+
+// apertureSequence 由各轮随机排列首尾相接组成的后端序列，按需逐轮生成
+type apertureSequence struct {
+	base   []*backend.Backend // 按URL排序的全部后端，所有实例以相同的顺序开始排列
+	window int                // 每个实例的分段长度
+	rounds [][]*backend.Backend
+}
+
+// newApertureSequence 创建后端序列，window不大于后端数
+func newApertureSequence(backends []*backend.Backend, window int) *apertureSequence {
+	base := make([]*backend.Backend, len(backends))
+	copy(base, backends)
+	sort.Slice(base, func(i, j int) bool {
+		return base[i].URL.String() < base[j].URL.String()
+	})
+	return &apertureSequence{base: base, window: window}
+}
+
+// at 返回序列中第pos个位置的后端
+func (q *apertureSequence) at(pos int) *backend.Backend {
+	n := len(q.base)
+	for len(q.rounds) <= pos/n {
+		q.rounds = append(q.rounds, q.nextRound())
+	}
+	return q.rounds[pos/n][pos%n]
+}
+
+// nextRound 生成下一轮排列
+//
+// 跨越两轮的分段可能在上一轮末尾和本轮开头取到同一个后端，
+// 因此将上一轮末尾已被该分段取到的后端移出本轮开头，保证每个分段内的后端互不相同。
+func (q *apertureSequence) nextRound() []*backend.Backend {
+	n := len(q.base)
+	round := len(q.rounds)
+	permutation := make([]*backend.Backend, n)
+	copy(permutation, q.base)
+	rnd := rand.New(rand.NewSource(int64(round)))
+	rnd.Shuffle(n, func(i, j int) {
+		permutation[i], permutation[j] = permutation[j], permutation[i]
+	})
+	if round == 0 {
+		return permutation
+	}
+
+	// 跨越本轮起点的分段在上一轮中的部分
+	boundary := round * n
+	start := (boundary - 1) / q.window * q.window
+	head := start + q.window - boundary
+	if head <= 0 {
+		return permutation
+	}
+	tail := make(map[*backend.Backend]bool, boundary-start)
+	for _, b := range q.rounds[round-1][start-(round-1)*n:] {
+		tail[b] = true
+	}
+
+	// 分段长度不大于后端数，本轮中不在tail中的后端足以填满开头的head个位置
+	next := head
+	for i := 0; i < head; i++ {
+		if !tail[permutation[i]] {
+			continue
+		}
+		for tail[permutation[next]] {
+			next++
+		}
+		permutation[i], permutation[next] = permutation[next], permutation[i]
+		next++
+	}
+	return permutation
+}
+
+// rendezvous 以实例编号为键，取HRW得分最高的size个后端
+func (s *Subset) rendezvous(active []*backend.Backend, size int) []*backend.Backend {
+	isActive := make(map[*backend.Backend]bool, len(active))
+	for _, b := range active {
+		isActive[b] = true
+	}
+
+	members := make([]*backend.Backend, 0, size)
+	for _, b := range s.ranker.rankAll(strconv.Itoa(s.opts.InstanceID)) {
+		if len(members) == size {
+			break
+		}
+		if isActive[b] {
+			members = append(members, b)
+		}
+	}
+	return members
+}
+
+// sameMembers 判断子集成员是否相同
+func sameMembers(set map[*backend.Backend]bool, members []*backend.Backend) bool {
+	if len(set) != len(members) {
+		return false
+	}
+	for _, b := range members {
+		if !set[b] {
+			return false
+		}
+	}
+	return true
+}
+
+// OnResponse 后端属于当前子集时将响应转发给子集的算法
+func (s *Subset) OnResponse(res *http.Response, peer *backend.Backend) {
+	state := s.inner.Load()
+	if !state.members[peer] {
+		return
+	}
+	if hook, ok := state.alg.(ResponseHook); ok {
+		hook.OnResponse(res, peer)
+	}
+}
+
+// Name 返回算法名称
+func (s *Subset) Name() string {
+	return s.inner.Load().alg.Name()
+}
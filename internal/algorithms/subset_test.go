@@ -0,0 +1,58 @@
+package algorithms
+
+import (
+	"fmt"
+	"go-load-balancer/internal/backend"
+	"testing"
+)
+
+func TestApertureCoversAllBackendsEvenly(t *testing.T) {
+	tests := []struct {
+		backends, instances, size int
+	}{
+		{10, 4, 3},
+		{10, 3, 3},
+		{7, 5, 2},
+		{12, 5, 4},
+		{25, 7, 6},
+		{100, 9, 10},
+		{6, 2, 6},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d后端/%d实例/子集%d", tt.backends, tt.instances, tt.size), func(t *testing.T) {
+			backends := newTestBackends(t, tt.backends)
+			counts := make(map[*backend.Backend]int, len(backends))
+			for id := 0; id < tt.instances; id++ {
+				alg, err := NewSubset(backends, SubsetOptions{
+					InstanceID:    id,
+					InstanceCount: tt.instances,
+					Size:          tt.size,
+					Method:        SubsetAperture,
+				}, func(backends []*backend.Backend) (Algorithm, error) {
+					return NewRoundRobin(backends), nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				members := alg.(*Subset).inner.Load().members
+				if want := alg.(*Subset).subsetSize(tt.backends); len(members) != want {
+					t.Fatalf("实例%d的子集有%d个不同的后端，期望%d", id, len(members), want)
+				}
+				for b := range members {
+					counts[b]++
+				}
+			}
+
+			lo, hi := tt.instances, 0
+			for _, b := range backends {
+				lo, hi = min(lo, counts[b]), max(hi, counts[b])
+			}
+			if lo == 0 {
+				t.Errorf("有后端未被任何实例使用")
+			}
+			if hi-lo > 1 {
+				t.Errorf("各后端被使用的实例数在%d到%d之间，期望最多相差1", lo, hi)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/stats"
+	"log"
+	"net/http"
+	"sync/atomic"
+)
+
+// Tier 一个优先级层级的后端集合
+type Tier struct {
+	Name     string
+	Backends []*backend.Backend
+}
+
+// Tiered 按优先级层级选择后端，并在高优先级层级健康容量不足时自动切换到下一层级
+//
+// 层级按优先级从高到低排列，只有第一个健康容量(存活后端权重之和占该层总权重的比例)
+// 不低于minHealthyPercent的层级参与选择；所有层级都不满足时使用第一个仍有存活后端的层级。
+// 每个层级使用独立的算法实例，层级内的选择方式与未分层时相同。
+type Tiered struct {
+	tiers             []*backendGroup
+	minHealthyPercent float64
+	current           atomic.Int32 // 当前使用的层级下标，-1表示尚未选择
+}
+
+// NewTiered 创建分层算法，newAlg用于为每个层级创建算法实例
+func NewTiered(tiers []Tier, minHealthyPercent float64, newAlg func(backends []*backend.Backend) (Algorithm, error)) (Algorithm, error) {
+	t := &Tiered{minHealthyPercent: minHealthyPercent}
+	t.current.Store(-1)
+
+	for _, tier := range tiers {
+		group, err := newBackendGroup(tier.Name, tier.Backends, newAlg)
+		if err != nil {
+			return nil, err
+		}
+		t.tiers = append(t.tiers, group)
+	}
+	return t, nil
+}
+
+// Pick 在当前可用的最高优先级层级中选择后端
+func (t *Tiered) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	if len(t.tiers) == 0 {
+		return nil, ErrNoBackends
+	}
+
+	idx := t.selectTier()
+	if idx < 0 {
+		return nil, ErrNoAliveBackend
+	}
+	t.switchTo(idx)
+	return t.tiers[idx].alg.Pick(ctx, req)
+}
+
+// selectTier 返回应当使用的层级下标，没有存活后端时返回-1
+func (t *Tiered) selectTier() int {
+	fallback := -1
+	for i, tier := range t.tiers {
+		total, healthy := tier.capacity()
+		if healthy == 0 {
+			continue
+		}
+		if float64(healthy)*100 >= t.minHealthyPercent*float64(total) {
+			return i
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+	}
+	return fallback
+}
+
+// switchTo 记录层级切换
+func (t *Tiered) switchTo(idx int) {
+	prev := t.current.Swap(int32(idx))
+	if prev == int32(idx) {
+		return
+	}
+
+	to := t.tiers[idx].name
+	collector := stats.GetPrometheusCollector()
+	if prev >= 0 {
+		from := t.tiers[prev].name
+		log.Printf("优先级层级切换: %s -> %s", from, to)
+		collector.RecordTierSwitch(from, to)
+	}
+	collector.SetActiveTier(to)
+}
+
+// UpdateBackends 将各层级的活跃后端变化转发给对应层级的算法
+func (t *Tiered) UpdateBackends(active []*backend.Backend) {
+	for _, tier := range t.tiers {
+		tier.updateBackends(active)
+	}
+}
+
+// OnResponse 将响应转发给后端所在层级的算法
+func (t *Tiered) OnResponse(res *http.Response, peer *backend.Backend) {
+	for _, tier := range t.tiers {
+		if tier.onResponse(res, peer) {
+			return
+		}
+	}
+}
+
+// Name 返回算法名称
+func (t *Tiered) Name() string {
+	if len(t.tiers) == 0 {
+		return "tiered"
+	}
+	return t.tiers[0].alg.Name()
+}
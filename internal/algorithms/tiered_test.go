@@ -0,0 +1,117 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"testing"
+)
+
+// newTestTiered 创建主备两个层级的分层算法，层级内使用轮询
+func newTestTiered(t *testing.T, primary, standby []*backend.Backend, minHealthyPercent float64) Algorithm {
+	t.Helper()
+	tiers := []Tier{
+		{Name: "tiered-test-primary", Backends: primary},
+		{Name: "tiered-test-standby", Backends: standby},
+	}
+	alg, err := NewTiered(tiers, minHealthyPercent, func(backends []*backend.Backend) (Algorithm, error) {
+		return NewRoundRobin(backends), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return alg
+}
+
+// assertPicksFrom 断言连续多次选择的后端都属于指定层级
+func assertPicksFrom(t *testing.T, alg Algorithm, tier []*backend.Backend, tierName string) {
+	t.Helper()
+	members := make(map[*backend.Backend]bool, len(tier))
+	for _, b := range tier {
+		members[b] = true
+	}
+	for i := 0; i < 20; i++ {
+		b, err := alg.Pick(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !members[b] {
+			t.Fatalf("选中了 %s，期望使用%s层级", b.URL.Host, tierName)
+		}
+		if !b.IsAlive() {
+			t.Fatalf("选中了不可用的后端 %s", b.URL.Host)
+		}
+	}
+}
+
+func TestTieredFailoverAndFailback(t *testing.T) {
+	backends := newTestBackends(t, 6)
+	primary, standby := backends[:4], backends[4:]
+	alg := newTestTiered(t, primary, standby, 50)
+
+	toStandby := map[string]string{"from": "tiered-test-primary", "to": "tiered-test-standby"}
+	toPrimary := map[string]string{"from": "tiered-test-standby", "to": "tiered-test-primary"}
+	failovers := counterValue(t, "go_lb_tier_switches_total", toStandby)
+	failbacks := counterValue(t, "go_lb_tier_switches_total", toPrimary)
+
+	assertPicksFrom(t, alg, primary, "主")
+
+	// 健康容量恰好等于阈值时仍使用主层级
+	primary[0].SetAlive(false)
+	primary[1].SetAlive(false)
+	assertPicksFrom(t, alg, primary, "主")
+	if got := counterValue(t, "go_lb_tier_switches_total", toStandby) - failovers; got != 0 {
+		t.Fatalf("健康容量未低于阈值时发生了%v次层级切换", got)
+	}
+
+	// 低于阈值后切换到备用层级，且只记录一次切换
+	primary[2].SetAlive(false)
+	assertPicksFrom(t, alg, standby, "备用")
+	if got := counterValue(t, "go_lb_tier_switches_total", toStandby) - failovers; got != 1 {
+		t.Fatalf("切换到备用层级的计数增加了%v，期望1", got)
+	}
+
+	// 主层级恢复到阈值后切换回来
+	primary[0].SetAlive(true)
+	assertPicksFrom(t, alg, primary, "主")
+	if got := counterValue(t, "go_lb_tier_switches_total", toPrimary) - failbacks; got != 1 {
+		t.Fatalf("切换回主层级的计数增加了%v，期望1", got)
+	}
+}
+
+func TestTieredWeightedCapacity(t *testing.T) {
+	backends := newTestBackends(t, 4)
+	primary, standby := backends[:3], backends[3:]
+	primary[0].Weight = 8
+	alg := newTestTiered(t, primary, standby, 70)
+
+	// 健康容量按权重计算：两个权重为1的后端不可用后仍有80%的容量
+	primary[1].SetAlive(false)
+	primary[2].SetAlive(false)
+	assertPicksFrom(t, alg, primary, "主")
+
+	primary[0].SetAlive(false)
+	primary[1].SetAlive(true)
+	primary[2].SetAlive(true)
+	assertPicksFrom(t, alg, standby, "备用")
+}
+
+func TestTieredFallsBackToFirstTierWithAliveBackends(t *testing.T) {
+	backends := newTestBackends(t, 6)
+	primary, standby := backends[:4], backends[4:]
+	alg := newTestTiered(t, primary, standby, 60)
+
+	// 所有层级都低于阈值时，使用第一个仍有存活后端的层级
+	for _, b := range primary[:3] {
+		b.SetAlive(false)
+	}
+	standby[0].SetAlive(false)
+	assertPicksFrom(t, alg, primary, "主")
+
+	primary[3].SetAlive(false)
+	assertPicksFrom(t, alg, standby, "备用")
+
+	standby[1].SetAlive(false)
+	if _, err := alg.Pick(context.Background(), nil); err != ErrNoAliveBackend {
+		t.Fatalf("所有后端不可用时返回 %v，期望 %v", err, ErrNoAliveBackend)
+	}
+}
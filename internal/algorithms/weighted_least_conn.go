@@ -0,0 +1,54 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"net/http"
+)
+
+func init() {
+	RegisterAlgorithm("weighted_least_conn", noOptions(NewWeightedLeastConn))
+}
+
+// WeightedLeastConn 实现加权最少连接负载均衡算法
+//
+// 后端得分为 连接数/有效权重，选择得分最低的后端，权重为4的后端可承担4倍的并发请求。
+type WeightedLeastConn struct {
+	backends []*backend.Backend
+}
+
+// NewWeightedLeastConn 创建新的加权最少连接算法实例
+func NewWeightedLeastConn(backends []*backend.Backend) Algorithm {
+	return &WeightedLeastConn{
+		backends: backends,
+	}
+}
+
+// Pick 获取 连接数/权重 最小的后端服务器
+func (wlc *WeightedLeastConn) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	if len(wlc.backends) == 0 {
+		return nil, ErrNoBackends
+	}
+
+	var best *backend.Backend
+	var bestScore float64
+	for _, b := range wlc.backends {
+		if !b.IsAlive() {
+			continue
+		}
+		// 比较 (连接数+1)/有效权重，得分相同时权重大的优先
+		if score := loadScore(b, b.EffectiveWeight()); best == nil || score < bestScore {
+			best, bestScore = b, score
+		}
+	}
+
+	if best == nil {
+		return nil, ErrNoAliveBackend
+	}
+	return best, nil
+}
+
+// Name 返回算法名称
+func (wlc *WeightedLeastConn) Name() string {
+	return "weighted_least_conn"
+}
@@ -0,0 +1,35 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"testing"
+)
+
+func TestWeightedLeastConnConcurrentShare(t *testing.T) {
+	heavy, err := backend.NewBackend("http://10.0.0.1:8080", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	light, err := backend.NewBackend("http://10.0.0.2:8080", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wlc := NewWeightedLeastConn([]*backend.Backend{heavy, light})
+
+	// 模拟并发请求：每次选中后连接数加1且不释放
+	const requests = 1000
+	for i := 0; i < requests; i++ {
+		b, err := wlc.Pick(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.IncrementConnections()
+	}
+
+	ratio := float64(heavy.GetConnections()) / float64(light.GetConnections())
+	if ratio < 3.9 || ratio > 4.1 {
+		t.Errorf("权重4与权重1的并发连接之比为 %.2f (%d/%d)，期望约为4",
+			ratio, heavy.GetConnections(), light.GetConnections())
+	}
+}
@@ -0,0 +1,185 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+)
+
+// 可用区感知路由的默认参数
+const (
+	DefaultZoneMinHealthyPercent = 70
+	DefaultZoneOverloadFactor    = 1.5
+)
+
+// ZoneOptions 可用区感知路由参数
+type ZoneOptions struct {
+	// LocalZone 负载均衡器实例所在的可用区
+	LocalZone string
+	// MinHealthyPercent 本地可用区健康容量不低于该百分比时全部流量留在本地，不大于0时使用默认值
+	MinHealthyPercent float64
+	// OverloadFactor 本地可用区单位容量的进行中请求数超过全局平均值的该倍数时视为过载
+	OverloadFactor float64
+}
+
+// ZoneAware 优先将请求路由到同可用区的后端
+//
+// 本地可用区健康且未过载时所有请求留在本地；本地健康容量低于MinHealthyPercent时，
+// 按本地健康容量比例保留流量，其余流量按其他可用区的健康容量比例溢出；
+// 本地过载时按过载程度进一步降低本地比例。每个可用区使用独立的算法实例。
+type ZoneAware struct {
+	local  *backendGroup   // 本地可用区，可能为nil
+	remote []*backendGroup // 其他可用区
+	opts   ZoneOptions
+}
+
+// NewZoneAware 创建可用区感知算法，newAlg用于为每个可用区创建算法实例
+func NewZoneAware(backends []*backend.Backend, opts ZoneOptions, newAlg func(backends []*backend.Backend) (Algorithm, error)) (Algorithm, error) {
+	if opts.MinHealthyPercent <= 0 {
+		opts.MinHealthyPercent = DefaultZoneMinHealthyPercent
+	}
+	if opts.OverloadFactor <= 0 {
+		opts.OverloadFactor = DefaultZoneOverloadFactor
+	}
+
+	zones := make(map[string][]*backend.Backend)
+	for _, b := range backends {
+		zones[b.Zone] = append(zones[b.Zone], b)
+	}
+	names := make([]string, 0, len(zones))
+	for name := range zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	z := &ZoneAware{opts: opts}
+	for _, name := range names {
+		group, err := newBackendGroup(name, zones[name], newAlg)
+		if err != nil {
+			return nil, err
+		}
+		if name == opts.LocalZone {
+			z.local = group
+		} else {
+			z.remote = append(z.remote, group)
+		}
+	}
+	return z, nil
+}
+
+// zoneLoad 可用区的容量和负载
+type zoneLoad struct {
+	group    *backendGroup
+	total    int   // 总权重
+	healthy  int   // 存活后端权重
+	inflight int64 // 存活后端的进行中请求数
+}
+
+// measure 统计可用区的容量和负载
+func measure(g *backendGroup) zoneLoad {
+	load := zoneLoad{group: g}
+	for _, b := range g.backends {
+		weight := weightOf(b)
+		load.total += weight
+		if b.IsAlive() {
+			load.healthy += weight
+			load.inflight += b.GetConnections()
+		}
+	}
+	return load
+}
+
+// Pick 按可用区感知策略选择后端
+func (z *ZoneAware) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	if z.local == nil && len(z.remote) == 0 {
+		return nil, ErrNoBackends
+	}
+
+	var local zoneLoad
+	if z.local != nil {
+		local = measure(z.local)
+	}
+	remote := make([]zoneLoad, 0, len(z.remote))
+	var remoteHealthy int
+	var remoteInflight int64
+	for _, g := range z.remote {
+		load := measure(g)
+		remote = append(remote, load)
+		remoteHealthy += load.healthy
+		remoteInflight += load.inflight
+	}
+
+	if local.healthy == 0 && remoteHealthy == 0 {
+		return nil, ErrNoAliveBackend
+	}
+
+	if remoteHealthy == 0 || rand.Float64() < z.localProbability(local, remoteHealthy, remoteInflight) {
+		return local.group.alg.Pick(ctx, req)
+	}
+
+	// 按健康容量比例选择其他可用区
+	n := rand.IntN(remoteHealthy)
+	for _, load := range remote {
+		if n < load.healthy {
+			return load.group.alg.Pick(ctx, req)
+		}
+		n -= load.healthy
+	}
+	return remote[len(remote)-1].group.alg.Pick(ctx, req)
+}
+
+// localProbability 计算请求留在本地可用区的概率
+func (z *ZoneAware) localProbability(local zoneLoad, remoteHealthy int, remoteInflight int64) float64 {
+	if local.healthy == 0 {
+		return 0
+	}
+
+	// 本地健康容量不足时按健康比例保留流量
+	p := 1.0
+	healthyRatio := float64(local.healthy) / float64(local.total)
+	if healthyRatio*100 < z.opts.MinHealthyPercent {
+		p = healthyRatio
+	}
+
+	// 本地单位容量负载超过全局平均值的OverloadFactor倍时按比例降低
+	localLoad := float64(local.inflight) / float64(local.healthy)
+	avgLoad := float64(local.inflight+remoteInflight) / float64(local.healthy+remoteHealthy)
+	if limit := avgLoad * z.opts.OverloadFactor; localLoad > limit && limit > 0 {
+		p *= limit / localLoad
+	}
+	return p
+}
+
+// UpdateBackends 将各可用区的活跃后端变化转发给对应可用区的算法
+func (z *ZoneAware) UpdateBackends(active []*backend.Backend) {
+	for _, g := range z.groups() {
+		g.updateBackends(active)
+	}
+}
+
+// OnResponse 将响应转发给后端所在可用区的算法
+func (z *ZoneAware) OnResponse(res *http.Response, peer *backend.Backend) {
+	for _, g := range z.groups() {
+		if g.onResponse(res, peer) {
+			return
+		}
+	}
+}
+
+// groups 返回所有可用区分组
+func (z *ZoneAware) groups() []*backendGroup {
+	if z.local == nil {
+		return z.remote
+	}
+	return append([]*backendGroup{z.local}, z.remote...)
+}
+
+// Name 返回算法名称
+func (z *ZoneAware) Name() string {
+	if g := z.groups(); len(g) > 0 {
+		return g[0].alg.Name()
+	}
+	return "zone_aware"
+}
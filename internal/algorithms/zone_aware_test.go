@@ -0,0 +1,122 @@
+package algorithms
+
+import (
+	"context"
+	"go-load-balancer/internal/backend"
+	"math"
+	"testing"
+)
+
+// newTestZoneAware 创建本地可用区为zone-a的可用区感知算法，前n个后端位于zone-a，其余位于zone-b
+func newTestZoneAware(t *testing.T, backends []*backend.Backend, n int) Algorithm {
+	t.Helper()
+	for i, b := range backends {
+		if i < n {
+			b.Zone = "zone-a"
+		} else {
+			b.Zone = "zone-b"
+		}
+	}
+	alg, err := NewZoneAware(backends, ZoneOptions{LocalZone: "zone-a"}, func(backends []*backend.Backend) (Algorithm, error) {
+		return NewRoundRobin(backends), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return alg
+}
+
+// localShare 返回多次选择中落在本地可用区的比例
+func localShare(t *testing.T, alg Algorithm, picks int) float64 {
+	t.Helper()
+	local := 0
+	for i := 0; i < picks; i++ {
+		b, err := alg.Pick(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !b.IsAlive() {
+			t.Fatalf("选中了不可用的后端 %s", b.URL.Host)
+		}
+		if b.Zone == "zone-a" {
+			local++
+		}
+	}
+	return float64(local) / float64(picks)
+}
+
+func TestZoneAwarePrefersLocalZone(t *testing.T) {
+	backends := newTestBackends(t, 8)
+	alg := newTestZoneAware(t, backends, 4)
+
+	if share := localShare(t, alg, 1000); share != 1 {
+		t.Fatalf("本地可用区健康时本地占比为 %.3f，期望全部留在本地", share)
+	}
+
+	// 健康容量不低于默认阈值70%时仍全部留在本地
+	backends[0].SetAlive(false)
+	if share := localShare(t, alg, 1000); share != 1 {
+		t.Fatalf("本地健康容量为75%%时本地占比为 %.3f，期望全部留在本地", share)
+	}
+}
+
+func TestZoneAwareSpillsBelowMinHealthyPercent(t *testing.T) {
+	backends := newTestBackends(t, 8)
+	alg := newTestZoneAware(t, backends, 4)
+
+	// 本地健康容量降到50%，低于阈值后按健康比例保留本地流量
+	backends[0].SetAlive(false)
+	backends[1].SetAlive(false)
+	if share := localShare(t, alg, 10000); math.Abs(share-0.5) > 0.03 {
+		t.Fatalf("本地健康容量为50%%时本地占比为 %.3f，期望接近 0.5", share)
+	}
+
+	// 本地全部不可用时全部溢出到其他可用区
+	backends[2].SetAlive(false)
+	backends[3].SetAlive(false)
+	if share := localShare(t, alg, 1000); share != 0 {
+		t.Fatalf("本地可用区不可用时本地占比为 %.3f，期望为0", share)
+	}
+
+	// 本地恢复后流量回到本地
+	for _, b := range backends[:4] {
+		b.SetAlive(true)
+	}
+	if share := localShare(t, alg, 1000); share != 1 {
+		t.Fatalf("本地可用区恢复后本地占比为 %.3f，期望全部留在本地", share)
+	}
+}
+
+func TestZoneAwareKeepsLocalWhenRemoteDown(t *testing.T) {
+	backends := newTestBackends(t, 8)
+	alg := newTestZoneAware(t, backends, 4)
+
+	// 其他可用区都不可用时，即使本地健康容量不足也只能留在本地
+	for _, b := range backends[1:] {
+		b.SetAlive(false)
+	}
+	backends[0].SetAlive(true)
+	if share := localShare(t, alg, 1000); share != 1 {
+		t.Fatalf("其他可用区不可用时本地占比为 %.3f，期望全部留在本地", share)
+	}
+
+	backends[0].SetAlive(false)
+	if _, err := alg.Pick(context.Background(), nil); err != ErrNoAliveBackend {
+		t.Fatalf("所有后端不可用时返回 %v，期望 %v", err, ErrNoAliveBackend)
+	}
+}
+
+func TestZoneAwareSpillsWhenLocalOverloaded(t *testing.T) {
+	backends := newTestBackends(t, 8)
+	alg := newTestZoneAware(t, backends, 4)
+
+	// 本地单位容量负载为10，全局平均为5，超过默认过载系数1.5倍的上限7.5，本地比例降为0.75
+	for _, b := range backends[:4] {
+		for i := 0; i < 10; i++ {
+			b.IncrementConnections()
+		}
+	}
+	if share := localShare(t, alg, 10000); math.Abs(share-0.75) > 0.03 {
+		t.Fatalf("本地过载时本地占比为 %.3f，期望接近 0.75", share)
+	}
+}
@@ -13,10 +13,19 @@ type Backend struct {
 	Status          string // "active", "retrying", "failed"
 	Weight          int
 	HealthCheckPath string `yaml:"health_check_path" mapstructure:"health_check_path"`
-	FailureCount    int    // 连续失败次数
+	Zone            string // 所在可用区
 	mux             sync.RWMutex
 	connections     int64
 	retryCh         chan struct{}
+	latency         peakEWMA      // 峰值敏感的延迟统计
+	avgLatency      movingAverage // 平均首字节时间
+	slowStart       SlowStart     // 慢启动配置
+	activeSince     time.Time     // 最近一次从不可用恢复为活跃的时间
+	load            loadState     // 后端上报的负载
+	outlier         outlierState  // 离群检测状态
+	breaker         breakerState  // 熔断器状态
+	health          healthCounter // 健康检查的连续成功/失败计数
+	probe           Probe         // 健康探测方式，为nil时按HealthCheckPath检查
 }
 
 // NewBackend 创建一个新的后端服务器实例
@@ -34,20 +43,44 @@ func NewBackend(rawURL string, weight int) (*Backend, error) {
 	}, nil
 }
 
-// IsAlive 检查后端是否存活
+// IsAlive 检查后端是否存活(处于活跃状态、未被离群检测驱逐且熔断器允许请求)
 func (b *Backend) IsAlive() bool {
 	b.mux.RLock()
 	defer b.mux.RUnlock()
-	return b.Status == StatusActive
+	return b.Status == StatusActive && !b.IsEjected() && b.circuitAllows()
+}
+
+// GetStatus 返回用于展示的后端状态，被离群检测驱逐时为ejected，熔断时为circuit_open
+func (b *Backend) GetStatus() string {
+	if b.IsEjected() {
+		return StatusEjected
+	}
+	if b.CircuitState() == CircuitOpen {
+		return StatusCircuitOpen
+	}
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	return b.Status
+}
+
+// status 返回由健康检查维护的后端状态，不含展示用的驱逐和熔断状态
+func (b *Backend) status() string {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	return b.Status
 }
 
 // SetStatus 设置后端状态
 func (b *Backend) SetStatus(status string) {
 	b.mux.Lock()
 	defer b.mux.Unlock()
+	if status == StatusActive && b.Status != StatusActive {
+		// 记录恢复时间，用于慢启动
+		b.activeSince = time.Now()
+	}
 	b.Status = status
 	if status == StatusActive {
-		b.FailureCount = 0
+		b.resetHealthCounter()
 	}
 }
 
@@ -75,6 +108,15 @@ func (b *Backend) DecrementConnections() {
 	atomic.AddInt64(&b.connections, -1)
 }
 
+// EffectiveWeight 返回后端当前的有效权重(配置权重 × 慢启动系数 × 负载反馈系数)
+func (b *Backend) EffectiveWeight() float64 {
+	weight := b.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+	return float64(weight) * b.SlowStartFactor() * b.LoadFactor()
+}
+
 // Addr 获取后端服务器地址(host:port)
 func (b *Backend) Addr() string {
 	return b.URL.Host
@@ -0,0 +1,255 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 熔断器状态
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+const (
+	circuitClosed int32 = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// 熔断器的默认参数
+const (
+	DefaultCircuitWindow           = 10 * time.Second
+	DefaultCircuitBuckets          = 10
+	DefaultCircuitMinRequests      = 20
+	DefaultCircuitErrorThreshold   = 50
+	DefaultCircuitTimeoutThreshold = 50
+	DefaultCircuitOpenDuration     = 30 * time.Second
+	DefaultCircuitHalfOpenProbes   = 3
+)
+
+// CircuitBreakerConfig 熔断器参数，零值表示使用默认值
+type CircuitBreakerConfig struct {
+	Window           time.Duration // 滚动统计窗口
+	MinRequests      int           // 窗口内请求数达到该值才会判断是否熔断
+	ErrorThreshold   float64       // 失败(5xx、转发失败)百分比阈值
+	TimeoutThreshold float64       // 超时(转发超时、慢响应)百分比阈值
+	SlowCallDuration time.Duration // 响应时间超过该值视为超时，0表示只统计转发超时
+	OpenDuration     time.Duration // 熔断持续时间，之后进入半开状态
+	HalfOpenProbes   int           // 半开状态下允许的探测请求数，全部成功后恢复
+}
+
+// circuitBucket 滚动窗口中的一个时间桶
+type circuitBucket struct {
+	slot     int64 // 桶对应的时间片序号
+	total    int
+	failures int
+	timeouts int
+}
+
+// breakerState 后端的熔断器状态
+type breakerState struct {
+	mu        sync.Mutex
+	state     atomic.Int32
+	probes    atomic.Int32 // 半开状态下进行中的探测请求数
+	maxProbes atomic.Int32 // 半开状态下允许的探测请求数
+	successes int          // 半开状态下成功的探测请求数
+	buckets   [DefaultCircuitBuckets]circuitBucket
+}
+
+// CircuitState 返回后端熔断器的状态
+func (b *Backend) CircuitState() string {
+	switch b.breaker.state.Load() {
+	case circuitOpen:
+		return CircuitOpen
+	case circuitHalfOpen:
+		return CircuitHalfOpen
+	default:
+		return CircuitClosed
+	}
+}
+
+// circuitAllows 熔断器是否允许向后端发送请求(不占用探测名额)
+func (b *Backend) circuitAllows() bool {
+	switch b.breaker.state.Load() {
+	case circuitOpen:
+		return false
+	case circuitHalfOpen:
+		return b.breaker.probes.Load() < b.breaker.maxProbes.Load()
+	default:
+		return true
+	}
+}
+
+// CircuitBreaker 为池中每个后端维护closed/open/half-open熔断器
+//
+// 关闭状态下按滚动窗口统计失败率和超时率，超过阈值时熔断；熔断期间后端不参与选择，
+// 经过OpenDuration后进入半开状态，只放行有限的探测请求，全部成功后恢复，任一失败则再次熔断。
+type CircuitBreaker struct {
+	pool *Pool
+	cfg  CircuitBreakerConfig
+}
+
+// NewCircuitBreaker 创建熔断器
+func NewCircuitBreaker(pool *Pool, cfg CircuitBreakerConfig) *CircuitBreaker {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultCircuitWindow
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = DefaultCircuitMinRequests
+	}
+	if cfg.ErrorThreshold <= 0 {
+		cfg.ErrorThreshold = DefaultCircuitErrorThreshold
+	}
+	if cfg.TimeoutThreshold <= 0 {
+		cfg.TimeoutThreshold = DefaultCircuitTimeoutThreshold
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = DefaultCircuitOpenDuration
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = DefaultCircuitHalfOpenProbes
+	}
+	return &CircuitBreaker{pool: pool, cfg: cfg}
+}
+
+// Acquire 请求发往后端之前调用，熔断时返回false；
+// probe表示该请求是半开状态下的探测请求，需要在完成时如实上报结果
+func (cb *CircuitBreaker) Acquire(b *Backend) (probe, ok bool) {
+	s := &b.breaker
+	switch s.state.Load() {
+	case circuitOpen:
+		return false, false
+	case circuitHalfOpen:
+		if s.probes.Add(1) > s.maxProbes.Load() {
+			s.probes.Add(-1)
+			return false, false
+		}
+		return true, true
+	default:
+		return false, true
+	}
+}
+
+// ObserveResponse 记录后端响应，5xx视为失败，超过SlowCallDuration视为超时
+func (cb *CircuitBreaker) ObserveResponse(b *Backend, probe bool, statusCode int, duration time.Duration) {
+	timeout := cb.cfg.SlowCallDuration > 0 && duration > cb.cfg.SlowCallDuration
+	cb.observe(b, probe, statusCode >= http.StatusInternalServerError, timeout)
+}
+
+// ObserveError 记录转发失败，客户端取消的请求不计入
+func (cb *CircuitBreaker) ObserveError(b *Backend, probe bool, err error) {
+	if errors.Is(err, context.Canceled) {
+		if probe {
+			// 释放探测名额，不影响熔断器状态
+			b.breaker.probes.Add(-1)
+		}
+		return
+	}
+
+	var netErr net.Error
+	timeout := errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+	cb.observe(b, probe, true, timeout)
+}
+
+// observe 更新熔断器状态
+func (cb *CircuitBreaker) observe(b *Backend, probe, failed, timeout bool) {
+	s := &b.breaker
+	s.mu.Lock()
+
+	changed := false
+	if probe {
+		s.probes.Add(-1)
+		changed = cb.observeProbeLocked(b, failed || timeout)
+	} else if s.state.Load() == circuitClosed {
+		total, failures, timeouts := cb.recordLocked(s, failed, timeout)
+		if total >= cb.cfg.MinRequests {
+			switch {
+			case float64(failures)*100 >= cb.cfg.ErrorThreshold*float64(total):
+				cb.tripLocked(b, "失败率过高")
+				changed = true
+			case float64(timeouts)*100 >= cb.cfg.TimeoutThreshold*float64(total):
+				cb.tripLocked(b, "超时率过高")
+				changed = true
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if changed {
+		cb.pool.notifyChange()
+	}
+}
+
+// observeProbeLocked 处理半开状态下探测请求的结果，返回熔断器状态是否变化，调用方需持有锁
+func (cb *CircuitBreaker) observeProbeLocked(b *Backend, failed bool) bool {
+	s := &b.breaker
+	if s.state.Load() != circuitHalfOpen {
+		return false
+	}
+	if failed {
+		cb.tripLocked(b, "半开状态探测失败")
+		return true
+	}
+
+	s.successes++
+	if s.successes < int(s.maxProbes.Load()) {
+		return false
+	}
+	s.buckets = [DefaultCircuitBuckets]circuitBucket{}
+	s.state.Store(circuitClosed)
+	log.Printf("服务 %s 探测请求全部成功，熔断器关闭", b.URL.Host)
+	return true
+}
+
+// recordLocked 将结果记入滚动窗口，返回窗口内的请求数、失败数和超时数
+func (cb *CircuitBreaker) recordLocked(s *breakerState, failed, timeout bool) (total, failures, timeouts int) {
+	width := cb.cfg.Window / DefaultCircuitBuckets
+	slot := time.Now().UnixNano() / int64(width)
+
+	bucket := &s.buckets[slot%DefaultCircuitBuckets]
+	if bucket.slot != slot {
+		*bucket = circuitBucket{slot: slot}
+	}
+	bucket.total++
+	if failed {
+		bucket.failures++
+	}
+	if timeout {
+		bucket.timeouts++
+	}
+
+	for _, bk := range s.buckets {
+		if slot-bk.slot < DefaultCircuitBuckets {
+			total += bk.total
+			failures += bk.failures
+			timeouts += bk.timeouts
+		}
+	}
+	return total, failures, timeouts
+}
+
+// tripLocked 熔断后端，经过OpenDuration后进入半开状态，调用方需持有锁
+func (cb *CircuitBreaker) tripLocked(b *Backend, reason string) {
+	s := &b.breaker
+	s.maxProbes.Store(int32(cb.cfg.HalfOpenProbes))
+	s.state.Store(circuitOpen)
+	log.Printf("服务 %s %s，熔断 %v", b.URL.Host, reason, cb.cfg.OpenDuration)
+
+	time.AfterFunc(cb.cfg.OpenDuration, func() {
+		s.mu.Lock()
+		s.successes = 0
+		s.state.Store(circuitHalfOpen)
+		s.mu.Unlock()
+
+		log.Printf("服务 %s 熔断器进入半开状态", b.URL.Host)
+		cb.pool.notifyChange()
+	})
+}
@@ -0,0 +1,232 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestCircuitBreaker 创建后端池和熔断器
+func newTestCircuitBreaker(backends []*Backend, cfg CircuitBreakerConfig) *CircuitBreaker {
+	return NewCircuitBreaker(NewPool(backends), cfg)
+}
+
+// waitCircuitState 等待后端熔断器进入指定状态
+func waitCircuitState(t *testing.T, b *Backend, state string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for b.CircuitState() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("熔断器状态为 %s，等待 %s 超时", b.CircuitState(), state)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// tripCircuit 通过连续失败使后端熔断
+func tripCircuit(t *testing.T, cb *CircuitBreaker, b *Backend) {
+	t.Helper()
+	for i := 0; i < cb.cfg.MinRequests; i++ {
+		cb.ObserveResponse(b, false, http.StatusInternalServerError, 0)
+	}
+	if b.CircuitState() != CircuitOpen {
+		t.Fatalf("连续失败后熔断器状态为 %s，期望 %s", b.CircuitState(), CircuitOpen)
+	}
+}
+
+func TestCircuitBreakerTripsOnErrorRate(t *testing.T) {
+	b := newTestBackend(t)
+	cb := newTestCircuitBreaker([]*Backend{b}, CircuitBreakerConfig{MinRequests: 4, ErrorThreshold: 50, OpenDuration: time.Hour})
+
+	// 请求数未达到MinRequests时不判断
+	cb.ObserveResponse(b, false, http.StatusOK, 0)
+	cb.ObserveResponse(b, false, http.StatusOK, 0)
+	cb.ObserveResponse(b, false, http.StatusInternalServerError, 0)
+	if b.CircuitState() != CircuitClosed {
+		t.Fatalf("请求数不足时熔断器状态为 %s", b.CircuitState())
+	}
+
+	// 4xx不计为失败
+	cb.ObserveResponse(b, false, http.StatusNotFound, 0)
+	cb.ObserveResponse(b, false, http.StatusBadGateway, 0)
+	if b.CircuitState() != CircuitClosed {
+		t.Fatalf("失败率为40%%时熔断器状态为 %s", b.CircuitState())
+	}
+
+	// 转发失败计为失败
+	cb.ObserveError(b, false, errors.New("connection refused"))
+	if b.CircuitState() != CircuitOpen {
+		t.Fatalf("失败率达到50%%后熔断器状态为 %s，期望 %s", b.CircuitState(), CircuitOpen)
+	}
+	if b.IsAlive() {
+		t.Error("熔断的后端仍视为存活")
+	}
+	if b.GetStatus() != StatusCircuitOpen {
+		t.Errorf("熔断的后端状态为 %s，期望 %s", b.GetStatus(), StatusCircuitOpen)
+	}
+	if _, ok := cb.Acquire(b); ok {
+		t.Error("熔断的后端仍然放行请求")
+	}
+}
+
+func TestCircuitBreakerIgnoresCanceledRequests(t *testing.T) {
+	b := newTestBackend(t)
+	cb := newTestCircuitBreaker([]*Backend{b}, CircuitBreakerConfig{MinRequests: 2, OpenDuration: time.Hour})
+	for i := 0; i < 10; i++ {
+		cb.ObserveError(b, false, context.Canceled)
+	}
+	if b.CircuitState() != CircuitClosed {
+		t.Errorf("客户端取消的请求导致熔断器状态为 %s", b.CircuitState())
+	}
+}
+
+func TestCircuitBreakerSlowCallThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		slowCall time.Duration
+		duration time.Duration
+		want     string
+	}{
+		{"慢响应超过阈值", 100 * time.Millisecond, 200 * time.Millisecond, CircuitOpen},
+		{"响应时间未超过阈值", 100 * time.Millisecond, 50 * time.Millisecond, CircuitClosed},
+		{"未配置慢响应阈值", 0, time.Minute, CircuitClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBackend(t)
+			cb := newTestCircuitBreaker([]*Backend{b}, CircuitBreakerConfig{
+				MinRequests:      10,
+				TimeoutThreshold: 50,
+				SlowCallDuration: tt.slowCall,
+				OpenDuration:     time.Hour,
+			})
+			for i := 0; i < 10; i++ {
+				duration := tt.duration
+				if i%2 == 1 {
+					duration = 0
+				}
+				cb.ObserveResponse(b, false, http.StatusOK, duration)
+			}
+			if got := b.CircuitState(); got != tt.want {
+				t.Errorf("熔断器状态为 %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerTripsOnTimeoutErrors(t *testing.T) {
+	b := newTestBackend(t)
+	cb := newTestCircuitBreaker([]*Backend{b}, CircuitBreakerConfig{
+		MinRequests:      4,
+		ErrorThreshold:   100,
+		TimeoutThreshold: 50,
+		OpenDuration:     time.Hour,
+	})
+	cb.ObserveResponse(b, false, http.StatusOK, 0)
+	cb.ObserveResponse(b, false, http.StatusOK, 0)
+	cb.ObserveError(b, false, context.DeadlineExceeded)
+	if b.CircuitState() != CircuitClosed {
+		t.Fatalf("请求数不足时熔断器状态为 %s", b.CircuitState())
+	}
+	cb.ObserveError(b, false, context.DeadlineExceeded)
+	if b.CircuitState() != CircuitOpen {
+		t.Errorf("超时率达到50%%后熔断器状态为 %s，期望 %s", b.CircuitState(), CircuitOpen)
+	}
+}
+
+func TestCircuitBreakerHalfOpenRecovers(t *testing.T) {
+	b := newTestBackend(t)
+	cb := newTestCircuitBreaker([]*Backend{b}, CircuitBreakerConfig{
+		MinRequests:    2,
+		OpenDuration:   20 * time.Millisecond,
+		HalfOpenProbes: 2,
+	})
+	tripCircuit(t, cb, b)
+
+	// 经过OpenDuration后进入半开状态
+	waitCircuitState(t, b, CircuitHalfOpen)
+	if !b.IsAlive() {
+		t.Fatal("半开状态下还有探测名额时后端应参与选择")
+	}
+
+	// 只放行HalfOpenProbes个探测请求
+	for i := 0; i < 2; i++ {
+		probe, ok := cb.Acquire(b)
+		if !ok || !probe {
+			t.Fatalf("第%d个探测请求返回 (%v, %v)，期望放行", i+1, probe, ok)
+		}
+	}
+	if _, ok := cb.Acquire(b); ok {
+		t.Fatal("探测名额用完后仍然放行请求")
+	}
+	if b.IsAlive() {
+		t.Fatal("探测名额用完后后端仍参与选择")
+	}
+
+	// 探测全部成功后关闭
+	cb.ObserveResponse(b, true, http.StatusOK, 0)
+	if b.CircuitState() != CircuitHalfOpen {
+		t.Fatalf("部分探测成功后熔断器状态为 %s，期望仍为 %s", b.CircuitState(), CircuitHalfOpen)
+	}
+	cb.ObserveResponse(b, true, http.StatusOK, 0)
+	if b.CircuitState() != CircuitClosed {
+		t.Fatalf("探测全部成功后熔断器状态为 %s，期望 %s", b.CircuitState(), CircuitClosed)
+	}
+	if probe, ok := cb.Acquire(b); !ok || probe {
+		t.Fatalf("关闭后请求返回 (%v, %v)，期望作为普通请求放行", probe, ok)
+	}
+
+	// 关闭时清空统计窗口，熔断前的失败不再计入
+	cb.ObserveResponse(b, false, http.StatusInternalServerError, 0)
+	if b.CircuitState() != CircuitClosed {
+		t.Errorf("恢复后单次失败导致熔断器状态为 %s", b.CircuitState())
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbeFailureReopens(t *testing.T) {
+	b := newTestBackend(t)
+	cb := newTestCircuitBreaker([]*Backend{b}, CircuitBreakerConfig{
+		MinRequests:    2,
+		OpenDuration:   20 * time.Millisecond,
+		HalfOpenProbes: 3,
+	})
+	tripCircuit(t, cb, b)
+	waitCircuitState(t, b, CircuitHalfOpen)
+
+	probe, ok := cb.Acquire(b)
+	if !ok || !probe {
+		t.Fatalf("探测请求返回 (%v, %v)，期望放行", probe, ok)
+	}
+	cb.ObserveError(b, true, errors.New("connection reset"))
+	if b.CircuitState() != CircuitOpen {
+		t.Fatalf("探测失败后熔断器状态为 %s，期望 %s", b.CircuitState(), CircuitOpen)
+	}
+
+	// 再次经过OpenDuration后重新进入半开状态
+	waitCircuitState(t, b, CircuitHalfOpen)
+}
+
+func TestCircuitBreakerCanceledProbeReleasesSlot(t *testing.T) {
+	b := newTestBackend(t)
+	cb := newTestCircuitBreaker([]*Backend{b}, CircuitBreakerConfig{
+		MinRequests:    2,
+		OpenDuration:   20 * time.Millisecond,
+		HalfOpenProbes: 1,
+	})
+	tripCircuit(t, cb, b)
+	waitCircuitState(t, b, CircuitHalfOpen)
+
+	if _, ok := cb.Acquire(b); !ok {
+		t.Fatal("探测请求未被放行")
+	}
+	cb.ObserveError(b, true, context.Canceled)
+	if b.CircuitState() != CircuitHalfOpen {
+		t.Fatalf("客户端取消探测请求后熔断器状态为 %s，期望 %s", b.CircuitState(), CircuitHalfOpen)
+	}
+	if _, ok := cb.Acquire(b); !ok {
+		t.Error("取消的探测请求未释放探测名额")
+	}
+}
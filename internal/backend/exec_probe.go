@@ -0,0 +1,102 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxExecOutput 脚本检查保留的输出上限
+const maxExecOutput = 4 << 10
+
+// execWaitDelay 超时结束进程后等待输出管道关闭的时间，避免子进程持有管道导致阻塞
+const execWaitDelay = time.Second
+
+// ExecCheck 脚本健康检查定义：退出码为0视为健康，标准输出记录为健康状态详情
+//
+// 命令的环境变量中包含BACKEND_URL、BACKEND_HOST和BACKEND_PORT。
+type ExecCheck struct {
+	Command []string // 命令及参数，不经过shell解释
+	Port    int      // 传给命令的端口，不大于0时使用流量端口
+}
+
+// execProbe 按ExecCheck定义执行脚本健康检查
+type execProbe struct {
+	check ExecCheck
+}
+
+// NewExecProbe 创建脚本健康探测
+func NewExecProbe(c ExecCheck) (Probe, error) {
+	if len(c.Command) == 0 || c.Command[0] == "" {
+		return nil, fmt.Errorf("脚本检查需要指定命令")
+	}
+	return &execProbe{check: c}, nil
+}
+
+// Check 实现Probe接口
+func (p *execProbe) Check(ctx context.Context, b *Backend) error {
+	_, err := p.CheckDetail(ctx, b)
+	return err
+}
+
+// CheckDetail 实现DetailedProbe接口，超时或健康检查停止时结束进程
+func (p *execProbe) CheckDetail(ctx context.Context, b *Backend) (string, error) {
+	host, port, _ := net.SplitHostPort(probeAddr(b, p.check.Port))
+
+	cmd := exec.CommandContext(ctx, p.check.Command[0], p.check.Command[1:]...)
+	cmd.Env = append(os.Environ(),
+		"BACKEND_URL="+b.URL.String(),
+		"BACKEND_HOST="+host,
+		"BACKEND_PORT="+port,
+	)
+	cmd.WaitDelay = execWaitDelay
+	killProcessGroup(cmd)
+
+	var stdout, stderr limitedBuffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	output := strings.TrimSpace(stdout.String())
+	if err == nil {
+		return output, nil
+	}
+
+	if ctx.Err() != nil {
+		return output, fmt.Errorf("脚本执行超时或被取消: %v", ctx.Err())
+	}
+	if output == "" {
+		output = strings.TrimSpace(stderr.String())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && output != "" {
+		return output, fmt.Errorf("%v: %s", err, output)
+	}
+	return output, err
+}
+
+// limitedBuffer 只保留前maxExecOutput字节的输出，其余丢弃
+//
+// 不嵌入bytes.Buffer，否则io.Copy会通过其ReadFrom方法绕过上限。
+type limitedBuffer struct {
+	buf bytes.Buffer
+}
+
+// Write 实现io.Writer接口，超出上限的部分被丢弃但不返回错误，避免命令因管道写失败而退出
+func (w *limitedBuffer) Write(data []byte) (int, error) {
+	if remain := maxExecOutput - w.buf.Len(); remain > 0 {
+		w.buf.Write(data[:min(len(data), remain)])
+	}
+	return len(data), nil
+}
+
+// String 返回保留的输出
+func (w *limitedBuffer) String() string {
+	return w.buf.String()
+}
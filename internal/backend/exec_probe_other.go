@@ -0,0 +1,8 @@
+//go:build !unix
+
+package backend
+
+import "os/exec"
+
+// killProcessGroup 非unix平台只结束命令进程本身
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,115 @@
+//go:build unix
+
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runExecProbe 创建脚本探测并在timeout内对后端执行一次检查，返回输出详情
+func runExecProbe(t *testing.T, c ExecCheck, b *Backend, timeout time.Duration) (string, error) {
+	t.Helper()
+	p, err := NewExecProbe(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return p.(DetailedProbe).CheckDetail(ctx, b)
+}
+
+func TestExecProbeExitCode(t *testing.T) {
+	b := newTestBackend(t)
+
+	tests := []struct {
+		name    string
+		script  string
+		detail  string
+		errText string // 为空表示期望健康
+	}{
+		{"退出码为0", "echo ' ready '", "ready", ""},
+		{"非0退出码", "exit 3", "", "exit status 3"},
+		{"失败时附带标准输出", "echo 'lag=30s'; echo 'db down' >&2; exit 1", "lag=30s", "exit status 1: lag=30s"},
+		{"无标准输出时附带标准错误", "echo 'db down' >&2; exit 2", "db down", "exit status 2: db down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail, err := runExecProbe(t, ExecCheck{Command: []string{"sh", "-c", tt.script}}, b, 5*time.Second)
+			if detail != tt.detail {
+				t.Errorf("输出详情为 %q，期望 %q", detail, tt.detail)
+			}
+			if tt.errText == "" {
+				if err != nil {
+					t.Errorf("脚本应视为健康，实际返回 %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errText) {
+				t.Errorf("脚本返回 %v，期望包含 %q", err, tt.errText)
+			}
+		})
+	}
+}
+
+func TestExecProbeEnvironment(t *testing.T) {
+	b := newTestBackend(t)
+	command := []string{"sh", "-c", `echo "$BACKEND_URL $BACKEND_HOST $BACKEND_PORT"`}
+
+	tests := []struct {
+		port int
+		want string
+	}{
+		{0, "http://10.0.0.1:8080 10.0.0.1 8080"},
+		{9090, "http://10.0.0.1:8080 10.0.0.1 9090"},
+	}
+	for _, tt := range tests {
+		detail, err := runExecProbe(t, ExecCheck{Command: command, Port: tt.port}, b, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detail != tt.want {
+			t.Errorf("Port为 %d 时环境变量为 %q，期望 %q", tt.port, detail, tt.want)
+		}
+	}
+}
+
+func TestExecProbeTimeout(t *testing.T) {
+	b := newTestBackend(t)
+
+	// 脚本在后台启动的子进程持有输出管道，超时后应随进程组一起被结束
+	start := time.Now()
+	_, err := runExecProbe(t, ExecCheck{Command: []string{"sh", "-c", "sleep 5 & sleep 5"}}, b, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "超时") {
+		t.Errorf("脚本返回 %v，期望超时错误", err)
+	}
+	if elapsed := time.Since(start); elapsed >= execWaitDelay {
+		t.Errorf("超时为100ms，探测耗时 %v", elapsed)
+	}
+}
+
+func TestExecProbeOutputLimit(t *testing.T) {
+	b := newTestBackend(t)
+	command := []string{"sh", "-c", "head -c 10000 /dev/zero | tr '\\0' a"}
+	detail, err := runExecProbe(t, ExecCheck{Command: command}, b, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detail) != maxExecOutput {
+		t.Errorf("输出长度为 %d，期望截断为 %d", len(detail), maxExecOutput)
+	}
+}
+
+func TestExecProbeInvalidCommand(t *testing.T) {
+	if _, err := NewExecProbe(ExecCheck{}); err == nil {
+		t.Error("未指定命令时未返回错误")
+	}
+
+	_, err := runExecProbe(t, ExecCheck{Command: []string{"/nonexistent/health-check"}}, newTestBackend(t), 5*time.Second)
+	if err == nil {
+		t.Error("命令不存在时探测应失败")
+	}
+}
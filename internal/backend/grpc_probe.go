@@ -0,0 +1,207 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// grpc.health.v1.HealthCheckResponse.ServingStatus
+const (
+	grpcHealthUnknown        = 0
+	grpcHealthServing        = 1
+	grpcHealthNotServing     = 2
+	grpcHealthServiceUnknown = 3
+)
+
+// grpcHealthMethod 标准gRPC健康检查方法
+const grpcHealthMethod = "/grpc.health.v1.Health/Check"
+
+// GRPCCheck gRPC健康检查定义，使用标准的grpc.health.v1.Health/Check协议
+type GRPCCheck struct {
+	Service   string   // 检查的服务名，为空表示检查整个服务器
+	Port      int      // 检查端口，不大于0时使用流量端口
+	Authority string   // 覆盖:authority伪头
+	UseTLS    bool     // 使用TLS，否则使用明文HTTP/2(h2c)
+	TLS       ProbeTLS // UseTLS时的TLS配置
+}
+
+// grpcProbe 通过HTTP/2直接发送gRPC请求执行健康检查，不依赖gRPC库
+type grpcProbe struct {
+	check   GRPCCheck
+	request []byte // 编码后的请求消息(含gRPC消息头)
+	client  *http.Client
+}
+
+// NewGRPCProbe 创建gRPC健康探测
+func NewGRPCProbe(c GRPCCheck) (Probe, error) {
+	protocols := new(http.Protocols)
+	transport := &http.Transport{DisableKeepAlives: true}
+	if c.UseTLS {
+		tlsConfig, err := c.TLS.config()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	transport.Protocols = protocols
+
+	return &grpcProbe{
+		check:   c,
+		request: encodeGRPCHealthRequest(c.Service),
+		client:  &http.Client{Transport: transport},
+	}, nil
+}
+
+// Check 实现Probe接口，SERVING视为健康，其余状态和gRPC错误视为失败
+func (p *grpcProbe) Check(ctx context.Context, b *Backend) error {
+	scheme := "http"
+	if p.check.UseTLS {
+		scheme = "https"
+	}
+	target := &url.URL{Scheme: scheme, Host: probeAddr(b, p.check.Port), Path: grpcHealthMethod}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(p.request))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(p.request))
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set("Grpc-Timeout", grpcTimeout(time.Until(deadline)))
+	}
+	if p.check.Authority != "" {
+		req.Host = p.check.Authority
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gRPC响应的HTTP状态码为 %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+	if err != nil {
+		return fmt.Errorf("读取gRPC响应失败: %v", err)
+	}
+
+	// grpc-status在trailer中，只有trailer的响应会放在响应头中
+	status := resp.Trailer.Get("Grpc-Status")
+	message := resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+		message = resp.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		if message, err := url.PathUnescape(message); err == nil && message != "" {
+			return fmt.Errorf("gRPC状态码 %s: %s", status, message)
+		}
+		return fmt.Errorf("gRPC状态码 %s", status)
+	}
+
+	serving, err := decodeGRPCHealthResponse(body)
+	if err != nil {
+		return err
+	}
+	switch serving {
+	case grpcHealthServing:
+		return nil
+	case grpcHealthNotServing:
+		return fmt.Errorf("服务状态为NOT_SERVING")
+	case grpcHealthServiceUnknown:
+		return fmt.Errorf("服务 %q 未知", p.check.Service)
+	default:
+		return fmt.Errorf("服务状态为UNKNOWN")
+	}
+}
+
+// grpcTimeout 按gRPC协议格式化超时时间
+func grpcTimeout(d time.Duration) string {
+	if d <= 0 {
+		d = time.Millisecond
+	}
+	return strconv.FormatInt(d.Milliseconds()+1, 10) + "m"
+}
+
+// encodeGRPCHealthRequest 编码HealthCheckRequest{service}并加上gRPC消息头
+func encodeGRPCHealthRequest(service string) []byte {
+	var msg []byte
+	if service != "" {
+		msg = append(msg, 0x0a) // 字段1，length-delimited
+		msg = binary.AppendUvarint(msg, uint64(len(service)))
+		msg = append(msg, service...)
+	}
+
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+// decodeGRPCHealthResponse 解析gRPC消息中的HealthCheckResponse，返回status字段
+func decodeGRPCHealthResponse(data []byte) (int, error) {
+	if len(data) < 5 {
+		return 0, fmt.Errorf("gRPC响应消息不完整")
+	}
+	if data[0] != 0 {
+		return 0, fmt.Errorf("不支持压缩的gRPC响应")
+	}
+	size := binary.BigEndian.Uint32(data[1:5])
+	if uint32(len(data)-5) < size {
+		return 0, fmt.Errorf("gRPC响应消息不完整")
+	}
+	msg := data[5 : 5+size]
+
+	status := grpcHealthUnknown
+	for len(msg) > 0 {
+		tag, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return 0, fmt.Errorf("无效的gRPC响应消息")
+		}
+		msg = msg[n:]
+
+		field, wireType := tag>>3, tag&0x7
+		switch wireType {
+		case 0: // varint
+			v, n := binary.Uvarint(msg)
+			if n <= 0 {
+				return 0, fmt.Errorf("无效的gRPC响应消息")
+			}
+			msg = msg[n:]
+			if field == 1 {
+				status = int(v)
+			}
+		case 1: // 64位
+			if len(msg) < 8 {
+				return 0, fmt.Errorf("无效的gRPC响应消息")
+			}
+			msg = msg[8:]
+		case 2: // length-delimited
+			l, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < l {
+				return 0, fmt.Errorf("无效的gRPC响应消息")
+			}
+			msg = msg[n+int(l):]
+		case 5: // 32位
+			if len(msg) < 4 {
+				return 0, fmt.Errorf("无效的gRPC响应消息")
+			}
+			msg = msg[4:]
+		default:
+			return 0, fmt.Errorf("无效的gRPC响应消息")
+		}
+	}
+	return status, nil
+}
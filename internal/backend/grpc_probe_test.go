@@ -0,0 +1,173 @@
+package backend
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// grpcHealthServer 按服务名返回健康状态的h2c gRPC健康检查服务器，
+// 未注册的服务与标准实现一样返回NOT_FOUND
+func grpcHealthServer(t *testing.T, statuses map[string]int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 || r.Method != http.MethodPost || r.URL.Path != grpcHealthMethod ||
+			r.Header.Get("Content-Type") != "application/grpc" || r.Header.Get("Grpc-Timeout") == "" {
+			t.Errorf("收到非预期的gRPC请求: %s %s %s %v", r.Proto, r.Method, r.URL.Path, r.Header)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		service := decodeTestHealthRequest(t, body)
+
+		w.Header().Set("Content-Type", "application/grpc")
+		status, ok := statuses[service]
+		if !ok {
+			// 仅包含trailer的响应，grpc-status放在响应头中
+			w.Header().Set("Grpc-Status", "5")
+			w.Header().Set("Grpc-Message", url.PathEscape("unknown service "+service))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		w.Write(encodeTestHealthResponse(status))
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// decodeTestHealthRequest 解析HealthCheckRequest中的服务名
+func decodeTestHealthRequest(t *testing.T, data []byte) string {
+	t.Helper()
+	if len(data) < 5 || int(binary.BigEndian.Uint32(data[1:5])) != len(data)-5 {
+		t.Errorf("gRPC请求消息头无效: %x", data)
+		return ""
+	}
+	msg := data[5:]
+	if len(msg) == 0 {
+		return ""
+	}
+	if msg[0] != 0x0a {
+		t.Errorf("gRPC请求消息字段无效: %x", msg)
+		return ""
+	}
+	l, n := binary.Uvarint(msg[1:])
+	return string(msg[1+n : 1+n+int(l)])
+}
+
+// encodeTestHealthResponse 编码HealthCheckResponse{status}，前面附带一个未知字段
+func encodeTestHealthResponse(status int) []byte {
+	msg := []byte{0x12, 0x02, 'o', 'k'} // 字段2，length-delimited
+	msg = append(msg, 0x08)             // 字段1，varint
+	msg = binary.AppendUvarint(msg, uint64(status))
+
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+func TestGRPCProbe(t *testing.T) {
+	srv := grpcHealthServer(t, map[string]int{
+		"":         grpcHealthServing,
+		"orders":   grpcHealthServing,
+		"payments": grpcHealthNotServing,
+		"legacy":   grpcHealthServiceUnknown,
+		"starting": grpcHealthUnknown,
+	})
+	b := probeBackend(t, srv.URL)
+
+	tests := []struct {
+		service string
+		errText string // 为空表示期望健康
+	}{
+		{"", ""},
+		{"orders", ""},
+		{"payments", "NOT_SERVING"},
+		{"legacy", "未知"},
+		{"starting", "UNKNOWN"},
+		{"inventory", "gRPC状态码 5: unknown service inventory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			p, err := NewGRPCProbe(GRPCCheck{Service: tt.service})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			err = p.Check(ctx, b)
+			if tt.errText == "" {
+				if err != nil {
+					t.Errorf("服务 %q 应健康，实际返回 %v", tt.service, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errText) {
+				t.Errorf("服务 %q 返回 %v，期望包含 %q", tt.service, err, tt.errText)
+			}
+		})
+	}
+}
+
+func TestGRPCProbePortAndAuthority(t *testing.T) {
+	hosts := make(chan string, 1)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts <- r.Host
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Write(encodeTestHealthResponse(grpcHealthServing))
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	// 后端流量端口不可用，通过Port指定检查端口
+	u, _ := url.Parse(srv.URL)
+	p, err := NewGRPCProbe(GRPCCheck{Port: atoi(t, u.Port()), Authority: "health.internal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check(context.Background(), probeBackend(t, "http://127.0.0.1:1")); err != nil {
+		t.Fatal(err)
+	}
+	if host := <-hosts; host != "health.internal" {
+		t.Errorf(":authority为 %q，期望 health.internal", host)
+	}
+}
+
+func TestDecodeGRPCHealthResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    int
+		wantErr bool
+	}{
+		{"SERVING", encodeTestHealthResponse(grpcHealthServing), grpcHealthServing, false},
+		{"空消息", []byte{0, 0, 0, 0, 0}, grpcHealthUnknown, false},
+		{"消息头不完整", []byte{0, 0, 0}, 0, true},
+		{"压缩的消息", []byte{1, 0, 0, 0, 0}, 0, true},
+		{"消息体不完整", []byte{0, 0, 0, 0, 2, 0x08}, 0, true},
+		{"字段值被截断", []byte{0, 0, 0, 0, 1, 0x08}, 0, true},
+		{"无效的类型", []byte{0, 0, 0, 0, 2, 0x0b, 0x01}, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := decodeGRPCHealthResponse(tt.data)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: 得到 (%d, %v)，期望 %d", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package backend
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
@@ -19,22 +21,45 @@ type HealthChecker struct {
 	timeout         time.Duration
 	stopCh          chan struct{}
 	retryCount      int
+	retryInterval   time.Duration
 	checkInProgress atomic.Bool // 防止健康检查并发执行
 }
 
-// NewHealthChecker 创建新的健康检查器
-func NewHealthChecker(pool *Pool, timeout time.Duration, retryCount int) *HealthChecker {
+// NewHealthChecker 创建新的健康检查器，retryCount和retryInterval不大于0时使用默认值
+func NewHealthChecker(pool *Pool, timeout time.Duration, retryCount int, retryInterval time.Duration) *HealthChecker {
 	if retryCount <= 0 {
 		retryCount = DefaultRetryCount
 	}
+	if retryInterval <= 0 {
+		retryInterval = DefaultRetryInterval
+	}
 	return &HealthChecker{
-		pool:       pool,
-		timeout:    timeout,
-		stopCh:     make(chan struct{}),
-		retryCount: retryCount,
+		pool:          pool,
+		timeout:       timeout,
+		stopCh:        make(chan struct{}),
+		retryCount:    retryCount,
+		retryInterval: retryInterval,
 	}
 }
 
+// maxCheckDuration 返回单个后端一轮带重试的检查最长耗时
+func (hc *HealthChecker) maxCheckDuration() time.Duration {
+	return hc.timeout*time.Duration(hc.retryCount) + hc.retryInterval*time.Duration(hc.retryCount-1)
+}
+
+// probeContext 返回单次探测的上下文，超时或健康检查停止时取消
+func (hc *HealthChecker) probeContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), hc.timeout)
+	go func() {
+		select {
+		case <-hc.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
 // checkTCP 执行TCP健康检查
 func (hc *HealthChecker) checkTCP(addr string) bool {
 	conn, err := net.DialTimeout("tcp", addr, hc.timeout)
@@ -59,7 +84,7 @@ func (hc *HealthChecker) checkHTTP(url string) bool {
 // performCheckWithRetry 带重试的健康检查(非阻塞版)
 func (hc *HealthChecker) performCheckWithRetry(checkFunc func() bool) bool {
 	resultChan := make(chan bool, 1)
-	timeoutChan := time.After(hc.timeout * time.Duration(hc.retryCount)) // 减少总超时时间
+	timeoutChan := time.After(hc.maxCheckDuration())
 
 	go func() {
 		for i := 0; i < hc.retryCount; i++ {
@@ -90,9 +115,9 @@ func (hc *HealthChecker) performCheckWithRetry(checkFunc func() bool) bool {
 
 			if i < hc.retryCount-1 {
 				log.Printf("健康检查失败(尝试 %d/%d)，%s后重试",
-					i+1, hc.retryCount, DefaultRetryInterval)
+					i+1, hc.retryCount, hc.retryInterval)
 				select {
-				case <-time.After(DefaultRetryInterval):
+				case <-time.After(hc.retryInterval):
 					continue
 				case <-hc.stopCh:
 					resultChan <- false
@@ -108,7 +133,7 @@ func (hc *HealthChecker) performCheckWithRetry(checkFunc func() bool) bool {
 	case result := <-resultChan:
 		return result
 	case <-timeoutChan:
-		log.Printf("健康检查总超时(超过 %v)", hc.timeout*time.Duration(hc.retryCount))
+		log.Printf("健康检查总超时(超过 %v)", hc.maxCheckDuration())
 		return false
 	case <-hc.stopCh:
 		return false
@@ -147,7 +172,7 @@ func (hc *HealthChecker) Start(interval time.Duration) {
 				select {
 				case <-done:
 					log.Println("执行健康检查完成")
-				case <-time.After(5 * time.Second): // 更短的超时时间
+				case <-time.After(hc.maxCheckDuration() + time.Second):
 					log.Println("健康检查监视超时，可能阻塞")
 					// 尽管超时，我们不中止健康检查，让它在后台继续，但下一次检查会跳过
 				}
@@ -159,6 +184,28 @@ func (hc *HealthChecker) Start(interval time.Duration) {
 	}()
 }
 
+// Recheck 立即重新检查后端，target为后端URL或host:port，为空时检查所有被隔离的后端
+//
+// 返回被检查的后端，检查完成后其状态已更新。
+func (hc *HealthChecker) Recheck(target string) ([]*Backend, error) {
+	var targets []*Backend
+	for _, b := range hc.pool.GetBackends() {
+		switch {
+		case target == "" && b.status() == StatusFailed:
+		case target != "" && (b.URL.String() == target || b.URL.Host == target):
+		default:
+			continue
+		}
+		targets = append(targets, b)
+	}
+	if target != "" && len(targets) == 0 {
+		return nil, fmt.Errorf("后端 %s 不存在", target)
+	}
+
+	hc.pool.Recheck(hc, targets)
+	return targets, nil
+}
+
 // Stop 停止健康检查
 func (hc *HealthChecker) Stop() {
 	close(hc.stopCh)
@@ -0,0 +1,61 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestBackend 创建权重为1的活跃后端
+func newTestBackend(t *testing.T) *Backend {
+	t.Helper()
+	return newTestBackends(t, 1)[0]
+}
+
+// newTestBackends 创建n个权重为1的活跃后端
+func newTestBackends(t *testing.T, n int) []*Backend {
+	t.Helper()
+	backends := make([]*Backend, n)
+	for i := range backends {
+		b, err := NewBackend(fmt.Sprintf("http://10.0.0.%d:8080", i+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		backends[i] = b
+	}
+	return backends
+}
+
+// stubProbe 结果可在测试中切换的健康探测
+type stubProbe struct {
+	healthy atomic.Bool
+	checks  atomic.Int32
+}
+
+func (p *stubProbe) Check(ctx context.Context, b *Backend) error {
+	p.checks.Add(1)
+	if p.healthy.Load() {
+		return nil
+	}
+	return errors.New("探测失败")
+}
+
+// newTestChecker 创建不重试的健康检查器，返回的后端池包含给定后端
+func newTestChecker(backends []*Backend) (*Pool, *HealthChecker) {
+	pool := NewPool(backends)
+	return pool, NewHealthChecker(pool, time.Second, 1, time.Millisecond)
+}
+
+// atoi 将测试中的端口字符串转换为整数
+func atoi(t *testing.T, s string) int {
+	t.Helper()
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
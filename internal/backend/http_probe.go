@@ -0,0 +1,232 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxProbeBody 健康检查读取响应体的上限
+const maxProbeBody = 64 << 10
+
+// HTTPCheck HTTP健康检查定义
+type HTTPCheck struct {
+	Method         string      // 请求方法，默认GET
+	Path           string      // 请求路径，可包含查询参数
+	Scheme         string      // http或https，为空时与后端URL一致
+	Port           int         // 检查端口，不大于0时使用流量端口
+	Host           string      // 覆盖Host头
+	Headers        http.Header // 附加请求头
+	Body           string      // 请求体
+	ExpectedStatus []string    // 接受的状态码或范围，如"204"、"200-399"，默认200
+	BodyContains   string      // 响应体需包含的子串
+	BodyRegex      string      // 响应体需匹配的正则表达式
+	JSONPath       string      // 响应体JSON中需断言的字段路径，如"status"、"checks.0.state"
+	JSONValue      string      // JSONPath处的期望值，为空时要求字段存在且不为null或false
+	TLS            ProbeTLS    // HTTPS检查的TLS配置
+}
+
+// StatusRange 闭区间的HTTP状态码范围
+type StatusRange struct {
+	Min, Max int
+}
+
+// ParseStatusRanges 解析状态码列表，每项可以是单个状态码、"200-399"形式的范围或以逗号分隔的多项
+func ParseStatusRanges(specs []string) ([]StatusRange, error) {
+	var ranges []StatusRange
+	for _, spec := range specs {
+		for _, item := range strings.Split(spec, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+
+			lo, hi, isRange := strings.Cut(item, "-")
+			first, err := strconv.Atoi(strings.TrimSpace(lo))
+			if err != nil {
+				return nil, fmt.Errorf("无效的状态码: %s", item)
+			}
+			last := first
+			if isRange {
+				if last, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+					return nil, fmt.Errorf("无效的状态码范围: %s", item)
+				}
+			}
+			if first < 100 || last > 599 || first > last {
+				return nil, fmt.Errorf("无效的状态码范围: %s", item)
+			}
+			ranges = append(ranges, StatusRange{Min: first, Max: last})
+		}
+	}
+	return ranges, nil
+}
+
+// httpProbe 按HTTPCheck定义执行HTTP健康检查
+type httpProbe struct {
+	check  HTTPCheck
+	status []StatusRange
+	regex  *regexp.Regexp
+	path   []string // 拆分后的JSONPath
+	client *http.Client
+}
+
+// NewHTTPProbe 创建HTTP健康探测
+func NewHTTPProbe(c HTTPCheck) (Probe, error) {
+	if c.Method == "" {
+		c.Method = http.MethodGet
+	}
+	c.Method = strings.ToUpper(c.Method)
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		c.Path = "/" + c.Path
+	}
+	if c.Scheme != "" && c.Scheme != "http" && c.Scheme != "https" {
+		return nil, fmt.Errorf("不支持的健康检查协议: %s", c.Scheme)
+	}
+
+	p := &httpProbe{check: c}
+
+	var err error
+	if p.status, err = ParseStatusRanges(c.ExpectedStatus); err != nil {
+		return nil, err
+	}
+	if len(p.status) == 0 {
+		p.status = []StatusRange{{Min: http.StatusOK, Max: http.StatusOK}}
+	}
+	if c.BodyRegex != "" {
+		if p.regex, err = regexp.Compile(c.BodyRegex); err != nil {
+			return nil, fmt.Errorf("无效的响应体正则表达式: %v", err)
+		}
+	}
+	if c.JSONPath != "" {
+		p.path = strings.Split(c.JSONPath, ".")
+	}
+
+	tlsConfig, err := c.TLS.config()
+	if err != nil {
+		return nil, err
+	}
+	p.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   tlsConfig,
+			DisableKeepAlives: true,
+		},
+		// 重定向的结果由状态码范围决定，不跟随
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return p, nil
+}
+
+// Check 实现Probe接口
+func (p *httpProbe) Check(ctx context.Context, b *Backend) error {
+	scheme := p.check.Scheme
+	if scheme == "" {
+		scheme = b.URL.Scheme
+	}
+	url := scheme + "://" + probeAddr(b, p.check.Port) + p.check.Path
+
+	var body io.Reader
+	if p.check.Body != "" {
+		body = strings.NewReader(p.check.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, p.check.Method, url, body)
+	if err != nil {
+		return err
+	}
+	for name, values := range p.check.Headers {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	if p.check.Host != "" {
+		req.Host = p.check.Host
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !p.statusAccepted(resp.StatusCode) {
+		return fmt.Errorf("状态码 %d 不在期望范围内", resp.StatusCode)
+	}
+	if p.check.BodyContains == "" && p.regex == nil && p.path == nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+	if err != nil {
+		return fmt.Errorf("读取响应体失败: %v", err)
+	}
+	if p.check.BodyContains != "" && !bytes.Contains(data, []byte(p.check.BodyContains)) {
+		return fmt.Errorf("响应体不包含 %q", p.check.BodyContains)
+	}
+	if p.regex != nil && !p.regex.Match(data) {
+		return fmt.Errorf("响应体不匹配 %s", p.regex)
+	}
+	if p.path != nil {
+		return p.checkJSON(data)
+	}
+	return nil
+}
+
+// statusAccepted 判断状态码是否在期望范围内
+func (p *httpProbe) statusAccepted(code int) bool {
+	for _, r := range p.status {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// checkJSON 断言响应体JSON中JSONPath处的值
+func (p *httpProbe) checkJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("响应体不是有效的JSON: %v", err)
+	}
+
+	for _, key := range p.path {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			child, ok := node[key]
+			if !ok {
+				return fmt.Errorf("JSON字段 %s 不存在", p.check.JSONPath)
+			}
+			v = child
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return fmt.Errorf("JSON字段 %s 不存在", p.check.JSONPath)
+			}
+			v = node[i]
+		default:
+			return fmt.Errorf("JSON字段 %s 不存在", p.check.JSONPath)
+		}
+	}
+
+	if p.check.JSONValue == "" {
+		if v == nil || v == false {
+			return fmt.Errorf("JSON字段 %s 的值为 %v", p.check.JSONPath, v)
+		}
+		return nil
+	}
+	if got := fmt.Sprint(v); got != p.check.JSONValue {
+		return fmt.Errorf("JSON字段 %s 的值为 %s，期望 %s", p.check.JSONPath, got, p.check.JSONValue)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package backend
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// probeBackend 创建指向测试服务器的后端
+func probeBackend(t *testing.T, rawURL string) *Backend {
+	t.Helper()
+	b, err := NewBackend(rawURL, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// runHTTPProbe 创建HTTP探测并对后端执行一次检查
+func runHTTPProbe(t *testing.T, c HTTPCheck, b *Backend) error {
+	t.Helper()
+	p, err := NewHTTPProbe(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return p.Check(ctx, b)
+}
+
+func TestHTTPProbeResponseMatching(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			io.WriteString(w, `{"status": "UP", "ready": true, "draining": false, "replicas": 3, "db": null, "checks": [{"state": "passing"}]}`)
+		case "/no-content":
+			w.WriteHeader(http.StatusNoContent)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/text":
+			io.WriteString(w, "version=1.4.2 state=healthy")
+		}
+	}))
+	defer srv.Close()
+	b := probeBackend(t, srv.URL)
+
+	tests := []struct {
+		name  string
+		check HTTPCheck
+		ok    bool
+	}{
+		{"默认期望200", HTTPCheck{Path: "/ok"}, true},
+		{"默认不接受204", HTTPCheck{Path: "/no-content"}, false},
+		{"状态码范围", HTTPCheck{Path: "/no-content", ExpectedStatus: []string{"200-299"}}, true},
+		{"多个状态码", HTTPCheck{Path: "/unavailable", ExpectedStatus: []string{"200, 503"}}, true},
+		{"状态码不在范围内", HTTPCheck{Path: "/unavailable", ExpectedStatus: []string{"200-399"}}, false},
+		{"不跟随重定向", HTTPCheck{Path: "/redirect"}, false},
+		{"接受重定向状态码", HTTPCheck{Path: "/redirect", ExpectedStatus: []string{"300-399"}}, true},
+		{"路径不带斜杠", HTTPCheck{Path: "ok"}, true},
+		{"包含子串", HTTPCheck{Path: "/text", BodyContains: "state=healthy"}, true},
+		{"不包含子串", HTTPCheck{Path: "/text", BodyContains: "state=draining"}, false},
+		{"匹配正则", HTTPCheck{Path: "/text", BodyRegex: `version=1\.\d+\.\d+`}, true},
+		{"不匹配正则", HTTPCheck{Path: "/text", BodyRegex: `version=2\.`}, false},
+		{"子串和正则同时满足", HTTPCheck{Path: "/text", BodyContains: "healthy", BodyRegex: `^version=`}, true},
+		{"JSON字段值", HTTPCheck{Path: "/ok", JSONPath: "status", JSONValue: "UP"}, true},
+		{"JSON字段值不符", HTTPCheck{Path: "/ok", JSONPath: "status", JSONValue: "DOWN"}, false},
+		{"JSON数组中的字段", HTTPCheck{Path: "/ok", JSONPath: "checks.0.state", JSONValue: "passing"}, true},
+		{"JSON数组越界", HTTPCheck{Path: "/ok", JSONPath: "checks.1.state"}, false},
+		{"JSON数值", HTTPCheck{Path: "/ok", JSONPath: "replicas", JSONValue: "3"}, true},
+		{"JSON字段存在且为true", HTTPCheck{Path: "/ok", JSONPath: "ready"}, true},
+		{"JSON字段为false", HTTPCheck{Path: "/ok", JSONPath: "draining"}, false},
+		{"JSON字段为null", HTTPCheck{Path: "/ok", JSONPath: "db"}, false},
+		{"JSON字段不存在", HTTPCheck{Path: "/ok", JSONPath: "missing"}, false},
+		{"JSON路径穿过标量", HTTPCheck{Path: "/ok", JSONPath: "status.value"}, false},
+		{"响应体不是JSON", HTTPCheck{Path: "/text", JSONPath: "status"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runHTTPProbe(t, tt.check, b)
+			if (err == nil) != tt.ok {
+				t.Errorf("探测结果为 %v，期望成功为 %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestHTTPProbeRequest(t *testing.T) {
+	type received struct {
+		method, path, query, host, token, body string
+	}
+	got := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{r.Method, r.URL.Path, r.URL.RawQuery, r.Host, r.Header.Get("X-Health-Token"), string(body)}
+	}))
+	defer srv.Close()
+
+	// 后端流量端口不可用，通过Port指定检查端口
+	u, _ := url.Parse(srv.URL)
+	b := probeBackend(t, "http://127.0.0.1:1")
+	port := u.Port()
+	check := HTTPCheck{
+		Method:  "post",
+		Path:    "/health?deep=1",
+		Port:    atoi(t, port),
+		Host:    "health.internal",
+		Headers: http.Header{"X-Health-Token": []string{"abc"}},
+		Body:    `{"probe": true}`,
+	}
+	if err := runHTTPProbe(t, check, b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := received{http.MethodPost, "/health", "deep=1", "health.internal", "abc", `{"probe": true}`}
+	if r := <-got; r != want {
+		t.Errorf("后端收到的请求为 %+v，期望 %+v", r, want)
+	}
+}
+
+func TestHTTPProbeTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	b := probeBackend(t, srv.URL)
+
+	// 将测试服务器的证书写入CA文件
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		tls  ProbeTLS
+		ok   bool
+	}{
+		{"不信任自签名证书", ProbeTLS{}, false},
+		{"跳过证书校验", ProbeTLS{InsecureSkipVerify: true}, true},
+		{"使用CA文件校验", ProbeTLS{CAFile: caFile}, true},
+		{"使用CA文件和匹配的ServerName", ProbeTLS{CAFile: caFile, ServerName: "example.com"}, true},
+		{"ServerName与证书不符", ProbeTLS{CAFile: caFile, ServerName: "wrong.test"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runHTTPProbe(t, HTTPCheck{TLS: tt.tls}, b)
+			if (err == nil) != tt.ok {
+				t.Errorf("探测结果为 %v，期望成功为 %v", err, tt.ok)
+			}
+		})
+	}
+
+	// 后端URL为http时，通过Scheme指定使用HTTPS检查
+	u, _ := url.Parse(srv.URL)
+	plain := probeBackend(t, "http://"+u.Host)
+	if err := runHTTPProbe(t, HTTPCheck{Scheme: "https", TLS: ProbeTLS{CAFile: caFile}}, plain); err != nil {
+		t.Errorf("指定https协议检查失败: %v", err)
+	}
+}
+
+func TestNewHTTPProbeValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		check HTTPCheck
+	}{
+		{"不支持的协议", HTTPCheck{Scheme: "ftp"}},
+		{"无效的状态码", HTTPCheck{ExpectedStatus: []string{"abc"}}},
+		{"无效的正则表达式", HTTPCheck{BodyRegex: "("}},
+		{"CA文件不存在", HTTPCheck{TLS: ProbeTLS{CAFile: filepath.Join(t.TempDir(), "missing.pem")}}},
+		{"客户端证书缺少私钥", HTTPCheck{TLS: ProbeTLS{CertFile: "cert.pem"}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewHTTPProbe(tt.check); err == nil {
+			t.Errorf("%s: 未返回错误", tt.name)
+		}
+	}
+}
+
+func TestParseStatusRanges(t *testing.T) {
+	tests := []struct {
+		specs   []string
+		want    []StatusRange
+		wantErr bool
+	}{
+		{[]string{"200"}, []StatusRange{{200, 200}}, false},
+		{[]string{"200-399"}, []StatusRange{{200, 399}}, false},
+		{[]string{"200, 204", "300 - 302"}, []StatusRange{{200, 200}, {204, 204}, {300, 302}}, false},
+		{[]string{""}, nil, false},
+		{[]string{"399-200"}, nil, true},
+		{[]string{"99"}, nil, true},
+		{[]string{"200-600"}, nil, true},
+		{[]string{"2xx"}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseStatusRanges(tt.specs)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("解析 %q 得到 (%v, %v)，期望 %v", tt.specs, got, err, tt.want)
+		}
+	}
+}
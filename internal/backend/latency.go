@@ -0,0 +1,108 @@
+package backend
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLatencyDecay Peak-EWMA的默认衰减时间常数
+	DefaultLatencyDecay = 10 * time.Second
+
+	// avgLatencyAlpha 平均延迟中新样本的权重
+	avgLatencyAlpha = 0.1
+)
+
+// peakEWMA 记录峰值敏感的指数加权移动平均延迟
+//
+// 新样本高于当前值时立即取新样本，否则按距离上次更新的时间指数衰减，
+// 因此后端变慢时能立刻反映，恢复后则逐渐回落。
+type peakEWMA struct {
+	mu    sync.Mutex
+	value float64 // 纳秒
+	stamp time.Time
+	decay time.Duration
+}
+
+// observe 加入一个延迟样本
+func (e *peakEWMA) observe(rtt time.Duration, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	sample := float64(rtt)
+	if e.stamp.IsZero() || sample > e.value {
+		e.value = sample
+	} else {
+		w := e.weight(now)
+		e.value = e.value*w + sample*(1-w)
+	}
+	e.stamp = now
+}
+
+// get 返回衰减到当前时刻的值，长时间没有样本时逐渐趋近于0
+func (e *peakEWMA) get(now time.Time) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stamp.IsZero() {
+		return 0
+	}
+	return e.value * e.weight(now)
+}
+
+// weight 计算旧值的权重
+func (e *peakEWMA) weight(now time.Time) float64 {
+	decay := e.decay
+	if decay <= 0 {
+		decay = DefaultLatencyDecay
+	}
+	elapsed := now.Sub(e.stamp)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Exp(-float64(elapsed) / float64(decay))
+}
+
+// movingAverage 按固定权重计算的指数移动平均延迟
+type movingAverage struct {
+	mu    sync.Mutex
+	value float64 // 纳秒
+	count int64
+}
+
+// observe 加入一个延迟样本
+func (m *movingAverage) observe(rtt time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.count == 0 {
+		m.value = float64(rtt)
+	} else {
+		m.value = m.value*(1-avgLatencyAlpha) + float64(rtt)*avgLatencyAlpha
+	}
+	m.count++
+}
+
+// get 返回当前平均值
+func (m *movingAverage) get() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.value
+}
+
+// ObserveLatency 记录一次请求的响应延迟(从开始代理到收到响应头)
+func (b *Backend) ObserveLatency(rtt time.Duration) {
+	b.latency.observe(rtt, time.Now())
+	b.avgLatency.observe(rtt)
+}
+
+// AvgTTFB 返回后端的平均首字节时间，尚无样本时返回0
+func (b *Backend) AvgTTFB() time.Duration {
+	return time.Duration(b.avgLatency.get())
+}
+
+// PeakEWMA 返回后端当前的峰值敏感EWMA延迟，尚无样本时返回0
+func (b *Backend) PeakEWMA() time.Duration {
+	return time.Duration(b.latency.get(time.Now()))
+}
@@ -0,0 +1,102 @@
+package backend
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// 负载反馈的默认参数
+const (
+	DefaultLoadFeedbackWindow   = 10 * time.Second
+	DefaultLoadFeedbackMinRatio = 0.1
+	DefaultLoadFeedbackMaxRatio = 1.0
+)
+
+// LoadFeedback 根据后端上报的利用率调整有效权重的参数
+//
+// 后端利用率为u时目标系数为1-u，并限制在[MinRatio, MaxRatio]内，
+// 再以Window为时间常数做指数平滑，避免权重随单个响应剧烈抖动。
+type LoadFeedback struct {
+	Window   time.Duration
+	MinRatio float64
+	MaxRatio float64
+}
+
+// loadState 后端负载反馈的平滑状态
+type loadState struct {
+	mu     sync.Mutex
+	cfg    LoadFeedback
+	factor float64
+	load   float64 // 最近一次上报的利用率
+	stamp  time.Time
+}
+
+// SetLoadFeedback 设置后端的负载反馈参数
+func (b *Backend) SetLoadFeedback(lf LoadFeedback) {
+	if lf.Window <= 0 {
+		lf.Window = DefaultLoadFeedbackWindow
+	}
+	if lf.MinRatio <= 0 {
+		lf.MinRatio = DefaultLoadFeedbackMinRatio
+	}
+	if lf.MaxRatio <= 0 {
+		lf.MaxRatio = DefaultLoadFeedbackMaxRatio
+	}
+	if lf.MaxRatio < lf.MinRatio {
+		lf.MaxRatio = lf.MinRatio
+	}
+
+	b.load.mu.Lock()
+	defer b.load.mu.Unlock()
+	b.load.cfg = lf
+}
+
+// ReportLoad 记录后端上报的利用率(0表示空闲，1表示满载)
+func (b *Backend) ReportLoad(utilization float64) {
+	now := time.Now()
+	utilization = math.Max(0, math.Min(1, utilization))
+
+	s := &b.load
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg := s.cfg
+	if cfg.Window <= 0 {
+		cfg = LoadFeedback{
+			Window:   DefaultLoadFeedbackWindow,
+			MinRatio: DefaultLoadFeedbackMinRatio,
+			MaxRatio: DefaultLoadFeedbackMaxRatio,
+		}
+	}
+
+	target := math.Max(cfg.MinRatio, math.Min(cfg.MaxRatio, 1-utilization))
+	if s.stamp.IsZero() {
+		s.factor = target
+	} else {
+		w := math.Exp(-float64(now.Sub(s.stamp)) / float64(cfg.Window))
+		s.factor = s.factor*w + target*(1-w)
+	}
+	s.load = utilization
+	s.stamp = now
+}
+
+// LoadFactor 返回负载反馈系数，后端尚未上报负载时为1
+func (b *Backend) LoadFactor() float64 {
+	b.load.mu.Lock()
+	defer b.load.mu.Unlock()
+	if b.load.stamp.IsZero() {
+		return 1
+	}
+	return b.load.factor
+}
+
+// ReportedLoad 返回后端最近一次上报的利用率，尚未上报时返回-1
+func (b *Backend) ReportedLoad() float64 {
+	b.load.mu.Lock()
+	defer b.load.mu.Unlock()
+	if b.load.stamp.IsZero() {
+		return -1
+	}
+	return b.load.load
+}
@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLoadFeedbackClampsToRatioRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         LoadFeedback
+		utilization float64
+		want        float64
+	}{
+		{"空闲", LoadFeedback{}, 0, DefaultLoadFeedbackMaxRatio},
+		{"半载", LoadFeedback{}, 0.5, 0.5},
+		{"满载限制在默认最小比例", LoadFeedback{}, 1, DefaultLoadFeedbackMinRatio},
+		{"超出1的利用率按满载处理", LoadFeedback{}, 3, DefaultLoadFeedbackMinRatio},
+		{"负利用率按空闲处理", LoadFeedback{}, -1, DefaultLoadFeedbackMaxRatio},
+		{"自定义最小比例", LoadFeedback{MinRatio: 0.3}, 0.9, 0.3},
+		{"自定义最大比例", LoadFeedback{MaxRatio: 0.8}, 0.1, 0.8},
+		{"最大比例小于最小比例时取最小比例", LoadFeedback{MinRatio: 0.5, MaxRatio: 0.2}, 0, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBackend(t)
+			b.SetLoadFeedback(tt.cfg)
+			b.ReportLoad(tt.utilization)
+			if got := b.LoadFactor(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("负载反馈系数为 %.4f，期望 %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFeedbackWithoutReports(t *testing.T) {
+	b := newTestBackend(t)
+	if got := b.LoadFactor(); got != 1 {
+		t.Errorf("尚未上报负载时系数为 %v，期望 1", got)
+	}
+	if got := b.ReportedLoad(); got != -1 {
+		t.Errorf("尚未上报负载时利用率为 %v，期望 -1", got)
+	}
+}
+
+func TestLoadFeedbackWindowDecay(t *testing.T) {
+	const window = 10 * time.Second
+	tests := []struct {
+		name    string
+		elapsed time.Duration // 距离上一次上报的时间
+		want    float64
+	}{
+		// 旧系数1按 exp(-elapsed/window) 保留，其余趋向满载时的目标系数0.1
+		{"立即再次上报", 0, 1},
+		{"经过一个时间窗口", window, 0.1 + 0.9*math.Exp(-1)},
+		{"经过三个时间窗口", 3 * window, 0.1 + 0.9*math.Exp(-3)},
+		{"经过很长时间", 100 * window, 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBackend(t)
+			b.SetLoadFeedback(LoadFeedback{Window: window})
+			b.ReportLoad(0)
+			b.load.stamp = time.Now().Add(-tt.elapsed)
+
+			b.ReportLoad(1)
+			if got := b.LoadFactor(); math.Abs(got-tt.want) > 1e-3 {
+				t.Errorf("负载反馈系数为 %.4f，期望 %.4f", got, tt.want)
+			}
+			if got := b.ReportedLoad(); got != 1 {
+				t.Errorf("最近上报的利用率为 %v，期望 1", got)
+			}
+		})
+	}
+}
+
+func TestLoadFeedbackScalesEffectiveWeight(t *testing.T) {
+	b := newTestBackend(t)
+	b.Weight = 10
+	b.ReportLoad(0.75)
+	if got := b.EffectiveWeight(); math.Abs(got-2.5) > 1e-9 {
+		t.Errorf("利用率为75%%时有效权重为 %v，期望 2.5", got)
+	}
+}
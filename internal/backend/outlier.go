@@ -0,0 +1,287 @@
+package backend
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 离群检测的默认参数
+const (
+	DefaultConsecutive5xx           = 5
+	DefaultConsecutiveGatewayErrors = 5
+	DefaultOutlierInterval          = 10 * time.Second
+	DefaultBaseEjectionTime         = 30 * time.Second
+	DefaultMaxEjectionTime          = 300 * time.Second
+	DefaultMaxEjectionPercent       = 10
+	DefaultSuccessRateMinHosts      = 5
+	DefaultSuccessRateRequestVolume = 100
+	DefaultSuccessRateStdevFactor   = 1.9
+)
+
+// OutlierDetection 被动健康检查(离群检测)参数，零值表示使用默认值
+type OutlierDetection struct {
+	Consecutive5xx           int           // 连续5xx响应次数阈值(代理错误也计入)
+	ConsecutiveGatewayErrors int           // 连续网关错误(502/503/504、连接失败)次数阈值
+	Interval                 time.Duration // 成功率统计周期，也是检查驱逐是否到期的周期
+	BaseEjectionTime         time.Duration // 基础驱逐时长，实际时长为 基础时长×驱逐次数
+	MaxEjectionTime          time.Duration // 驱逐时长上限
+	MaxEjectionPercent       float64       // 同时被驱逐的后端占比上限
+	SuccessRateMinHosts      int           // 参与成功率检测的最少后端数
+	SuccessRateRequestVolume int           // 后端在一个周期内参与成功率检测的最少请求数
+	SuccessRateStdevFactor   float64       // 成功率低于 均值-系数×标准差 时驱逐
+}
+
+// outlierState 后端的离群检测状态
+type outlierState struct {
+	mu                 sync.Mutex
+	consecutive5xx     int
+	consecutiveGateway int
+	success, total     int64 // 当前统计周期内的成功数和请求数
+	ejections          int   // 驱逐次数，决定驱逐时长，后端正常的周期内逐步衰减
+	ejectedUntil       time.Time
+	ejected            atomic.Bool
+}
+
+// IsEjected 返回后端当前是否被离群检测驱逐
+func (b *Backend) IsEjected() bool {
+	return b.outlier.ejected.Load()
+}
+
+// OutlierEjections 返回后端当前累计的驱逐次数
+func (b *Backend) OutlierEjections() int {
+	b.outlier.mu.Lock()
+	defer b.outlier.mu.Unlock()
+	return b.outlier.ejections
+}
+
+// OutlierDetector 根据真实流量的响应结果驱逐异常后端
+//
+// 与主动健康检查相互独立：被驱逐的后端在驱逐期间不参与选择，到期后自动恢复，
+// 期间主动健康检查仍照常进行。
+type OutlierDetector struct {
+	pool   *Pool
+	cfg    OutlierDetection
+	mu     sync.Mutex // 串行化驱逐决策，保证不超过驱逐比例上限
+	stopCh chan struct{}
+}
+
+// NewOutlierDetector 创建离群检测器
+func NewOutlierDetector(pool *Pool, cfg OutlierDetection) *OutlierDetector {
+	if cfg.Consecutive5xx <= 0 {
+		cfg.Consecutive5xx = DefaultConsecutive5xx
+	}
+	if cfg.ConsecutiveGatewayErrors <= 0 {
+		cfg.ConsecutiveGatewayErrors = DefaultConsecutiveGatewayErrors
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultOutlierInterval
+	}
+	if cfg.BaseEjectionTime <= 0 {
+		cfg.BaseEjectionTime = DefaultBaseEjectionTime
+	}
+	if cfg.MaxEjectionTime <= 0 {
+		cfg.MaxEjectionTime = DefaultMaxEjectionTime
+	}
+	if cfg.MaxEjectionTime < cfg.BaseEjectionTime {
+		cfg.MaxEjectionTime = cfg.BaseEjectionTime
+	}
+	if cfg.MaxEjectionPercent <= 0 {
+		cfg.MaxEjectionPercent = DefaultMaxEjectionPercent
+	}
+	if cfg.SuccessRateMinHosts <= 0 {
+		cfg.SuccessRateMinHosts = DefaultSuccessRateMinHosts
+	}
+	if cfg.SuccessRateRequestVolume <= 0 {
+		cfg.SuccessRateRequestVolume = DefaultSuccessRateRequestVolume
+	}
+	if cfg.SuccessRateStdevFactor <= 0 {
+		cfg.SuccessRateStdevFactor = DefaultSuccessRateStdevFactor
+	}
+
+	return &OutlierDetector{
+		pool:   pool,
+		cfg:    cfg,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// ObserveResponse 记录后端返回的响应状态码
+func (d *OutlierDetector) ObserveResponse(b *Backend, statusCode int) {
+	gateway := statusCode == http.StatusBadGateway ||
+		statusCode == http.StatusServiceUnavailable ||
+		statusCode == http.StatusGatewayTimeout
+	d.observe(b, statusCode < 500, gateway)
+}
+
+// ObserveError 记录转发到后端失败(连接被拒绝、重置、超时等)，按网关错误处理
+func (d *OutlierDetector) ObserveError(b *Backend) {
+	d.observe(b, false, true)
+}
+
+// observe 更新后端的连续错误计数，达到阈值时尝试驱逐
+func (d *OutlierDetector) observe(b *Backend, success, gateway bool) {
+	s := &b.outlier
+	s.mu.Lock()
+	s.total++
+	if success {
+		s.success++
+		s.consecutive5xx = 0
+		s.consecutiveGateway = 0
+	} else {
+		s.consecutive5xx++
+		if gateway {
+			s.consecutiveGateway++
+		} else {
+			s.consecutiveGateway = 0
+		}
+	}
+
+	var reason string
+	switch {
+	case s.consecutiveGateway >= d.cfg.ConsecutiveGatewayErrors:
+		reason = "连续网关错误"
+	case s.consecutive5xx >= d.cfg.Consecutive5xx:
+		reason = "连续5xx响应"
+	}
+	s.mu.Unlock()
+
+	if reason != "" && d.eject(b, reason) {
+		d.pool.notifyChange()
+	}
+}
+
+// eject 驱逐后端，超过驱逐比例上限或已被驱逐时返回false
+func (d *OutlierDetector) eject(b *Backend, reason string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if b.IsEjected() {
+		return false
+	}
+
+	backends := d.pool.GetBackends()
+	ejected := 0
+	for _, other := range backends {
+		if other.IsEjected() {
+			ejected++
+		}
+	}
+
+	s := &b.outlier
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 无论是否驱逐都重新累计连续错误，避免达到上限时每个错误都重复尝试
+	s.consecutive5xx = 0
+	s.consecutiveGateway = 0
+	if ejected >= d.maxEjections(len(backends)) {
+		log.Printf("服务 %s %s，但被驱逐的后端已达上限(%d/%d)，暂不驱逐", b.URL.Host, reason, ejected, len(backends))
+		return false
+	}
+
+	s.ejections++
+	duration := d.cfg.BaseEjectionTime * time.Duration(s.ejections)
+	if duration > d.cfg.MaxEjectionTime {
+		duration = d.cfg.MaxEjectionTime
+	}
+	s.ejectedUntil = time.Now().Add(duration)
+	s.ejected.Store(true)
+
+	log.Printf("服务 %s %s，被离群检测驱逐 %v", b.URL.Host, reason, duration)
+	return true
+}
+
+// maxEjections 返回最多可同时驱逐的后端数，后端多于一个时至少允许驱逐一个
+func (d *OutlierDetector) maxEjections(total int) int {
+	limit := int(float64(total) * d.cfg.MaxEjectionPercent / 100)
+	if limit < 1 && total > 1 {
+		limit = 1
+	}
+	return limit
+}
+
+// Start 启动周期性检查：恢复驱逐到期的后端并执行成功率检测
+func (d *OutlierDetector) Start() {
+	ticker := time.NewTicker(d.cfg.Interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.evaluate()
+			case <-d.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止离群检测
+func (d *OutlierDetector) Stop() {
+	close(d.stopCh)
+}
+
+// evaluate 执行一个周期的检查
+func (d *OutlierDetector) evaluate() {
+	now := time.Now()
+	backends := d.pool.GetBackends()
+	changed := false
+
+	type sample struct {
+		backend *Backend
+		rate    float64
+	}
+	var samples []sample
+
+	for _, b := range backends {
+		s := &b.outlier
+		s.mu.Lock()
+		if s.ejected.Load() {
+			if !now.Before(s.ejectedUntil) {
+				s.ejected.Store(false)
+				changed = true
+				log.Printf("服务 %s 驱逐到期，恢复参与负载均衡", b.URL.Host)
+			}
+		} else {
+			// 正常的周期内逐步降低驱逐次数，使偶发异常的后端不会被越罚越久
+			if s.ejections > 0 {
+				s.ejections--
+			}
+			if s.total >= int64(d.cfg.SuccessRateRequestVolume) {
+				samples = append(samples, sample{backend: b, rate: float64(s.success) / float64(s.total)})
+			}
+		}
+		s.success, s.total = 0, 0
+		s.mu.Unlock()
+	}
+
+	// 成功率检测：与同池后端的平均成功率比较
+	if len(samples) >= d.cfg.SuccessRateMinHosts {
+		var sum float64
+		for _, s := range samples {
+			sum += s.rate
+		}
+		mean := sum / float64(len(samples))
+
+		var variance float64
+		for _, s := range samples {
+			variance += (s.rate - mean) * (s.rate - mean)
+		}
+		stdev := math.Sqrt(variance / float64(len(samples)))
+
+		threshold := mean - d.cfg.SuccessRateStdevFactor*stdev
+		for _, s := range samples {
+			if s.rate < threshold && d.eject(s.backend, "成功率明显低于其他后端") {
+				changed = true
+			}
+		}
+	}
+
+	if changed {
+		d.pool.notifyChange()
+	}
+}
@@ -0,0 +1,244 @@
+package backend
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestOutlierDetector 创建后端池和离群检测器
+func newTestOutlierDetector(backends []*Backend, cfg OutlierDetection) *OutlierDetector {
+	return NewOutlierDetector(NewPool(backends), cfg)
+}
+
+// ejectedCount 返回被驱逐的后端数
+func ejectedCount(backends []*Backend) int {
+	n := 0
+	for _, b := range backends {
+		if b.IsEjected() {
+			n++
+		}
+	}
+	return n
+}
+
+func TestOutlierConsecutive5xx(t *testing.T) {
+	backends := newTestBackends(t, 2)
+	b := backends[0]
+	d := newTestOutlierDetector(backends, OutlierDetection{Consecutive5xx: 3, ConsecutiveGatewayErrors: 10})
+
+	// 中间的成功响应重新开始计数
+	d.ObserveResponse(b, http.StatusInternalServerError)
+	d.ObserveResponse(b, http.StatusInternalServerError)
+	d.ObserveResponse(b, http.StatusOK)
+	d.ObserveResponse(b, http.StatusInternalServerError)
+	d.ObserveResponse(b, http.StatusInternalServerError)
+	if b.IsEjected() {
+		t.Fatal("连续5xx未达到阈值时被驱逐")
+	}
+
+	// 代理错误同样计入连续5xx
+	d.ObserveError(b)
+	if !b.IsEjected() {
+		t.Fatal("连续3次5xx后未被驱逐")
+	}
+	if b.IsAlive() {
+		t.Error("被驱逐的后端仍视为存活")
+	}
+	if b.GetStatus() != StatusEjected {
+		t.Errorf("被驱逐的后端状态为 %s，期望 %s", b.GetStatus(), StatusEjected)
+	}
+	if backends[1].IsEjected() {
+		t.Error("正常的后端被驱逐")
+	}
+}
+
+func TestOutlierConsecutiveGatewayErrors(t *testing.T) {
+	backends := newTestBackends(t, 2)
+	b := backends[0]
+	d := newTestOutlierDetector(backends, OutlierDetection{Consecutive5xx: 10, ConsecutiveGatewayErrors: 3})
+
+	// 非网关错误的5xx重新开始网关错误计数
+	d.ObserveResponse(b, http.StatusBadGateway)
+	d.ObserveError(b)
+	d.ObserveResponse(b, http.StatusInternalServerError)
+	d.ObserveResponse(b, http.StatusServiceUnavailable)
+	d.ObserveResponse(b, http.StatusGatewayTimeout)
+	if b.IsEjected() {
+		t.Fatal("连续网关错误未达到阈值时被驱逐")
+	}
+
+	d.ObserveError(b)
+	if !b.IsEjected() {
+		t.Fatal("连续3次网关错误后未被驱逐")
+	}
+}
+
+func TestOutlierIgnores4xx(t *testing.T) {
+	backends := newTestBackends(t, 2)
+	d := newTestOutlierDetector(backends, OutlierDetection{Consecutive5xx: 2, ConsecutiveGatewayErrors: 2})
+	for i := 0; i < 10; i++ {
+		d.ObserveResponse(backends[0], http.StatusNotFound)
+	}
+	if backends[0].IsEjected() {
+		t.Error("4xx响应导致后端被驱逐")
+	}
+}
+
+func TestOutlierSuccessRateEjection(t *testing.T) {
+	backends := newTestBackends(t, 6)
+	d := newTestOutlierDetector(backends, OutlierDetection{
+		SuccessRateMinHosts:      5,
+		SuccessRateRequestVolume: 50,
+		Consecutive5xx:           1000,
+		ConsecutiveGatewayErrors: 1000,
+	})
+
+	// 前5个后端成功率为98%，最后一个为60%；错误分散出现，不会触发连续错误驱逐
+	for i, b := range backends {
+		for j := 0; j < 100; j++ {
+			status := http.StatusOK
+			if (i < 5 && j%50 == 0) || (i == 5 && j%5 < 2) {
+				status = http.StatusInternalServerError
+			}
+			d.ObserveResponse(b, status)
+		}
+	}
+	if n := ejectedCount(backends); n != 0 {
+		t.Fatalf("成功率检测之前有%d个后端被驱逐", n)
+	}
+
+	d.evaluate()
+	if !backends[5].IsEjected() {
+		t.Error("成功率明显偏低的后端未被驱逐")
+	}
+	if n := ejectedCount(backends); n != 1 {
+		t.Errorf("被驱逐的后端数为%d，期望只驱逐成功率偏低的一个", n)
+	}
+}
+
+func TestOutlierSuccessRateRequiresVolumeAndHosts(t *testing.T) {
+	tests := []struct {
+		name     string
+		hosts    int
+		requests int
+	}{
+		{"请求数不足", 6, 20},
+		{"后端数不足", 4, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backends := newTestBackends(t, tt.hosts)
+			d := newTestOutlierDetector(backends, OutlierDetection{
+				SuccessRateMinHosts:      5,
+				SuccessRateRequestVolume: 50,
+				Consecutive5xx:           1000,
+				ConsecutiveGatewayErrors: 1000,
+			})
+			bad := backends[len(backends)-1]
+			for _, b := range backends {
+				for j := 0; j < tt.requests; j++ {
+					status := http.StatusOK
+					if b == bad && j%2 == 0 {
+						status = http.StatusInternalServerError
+					}
+					d.ObserveResponse(b, status)
+				}
+			}
+			d.evaluate()
+			if n := ejectedCount(backends); n != 0 {
+				t.Errorf("样本不足时驱逐了%d个后端", n)
+			}
+		})
+	}
+}
+
+func TestOutlierMaxEjectionPercent(t *testing.T) {
+	backends := newTestBackends(t, 10)
+	d := newTestOutlierDetector(backends, OutlierDetection{Consecutive5xx: 1, MaxEjectionPercent: 20})
+
+	for _, b := range backends[:5] {
+		d.ObserveResponse(b, http.StatusInternalServerError)
+	}
+	if n := ejectedCount(backends); n != 2 {
+		t.Fatalf("被驱逐的后端数为%d，期望不超过20%%即2个", n)
+	}
+
+	// 有后端恢复后，其他异常后端可以被驱逐
+	backends[0].outlier.ejectedUntil = time.Now().Add(-time.Second)
+	d.evaluate()
+	d.ObserveResponse(backends[4], http.StatusInternalServerError)
+	if !backends[4].IsEjected() {
+		t.Error("驱逐名额空出后异常后端未被驱逐")
+	}
+	if n := ejectedCount(backends); n != 2 {
+		t.Errorf("被驱逐的后端数为%d，期望2", n)
+	}
+}
+
+func TestOutlierMinimumOneEjection(t *testing.T) {
+	// 按比例计算不足一个时，后端多于一个仍允许驱逐一个
+	backends := newTestBackends(t, 3)
+	d := newTestOutlierDetector(backends, OutlierDetection{Consecutive5xx: 1, MaxEjectionPercent: 10})
+	for _, b := range backends {
+		d.ObserveResponse(b, http.StatusInternalServerError)
+	}
+	if n := ejectedCount(backends); n != 1 {
+		t.Errorf("被驱逐的后端数为%d，期望1", n)
+	}
+
+	// 只有一个后端且按比例不足一个时不驱逐
+	single := newTestBackends(t, 1)
+	d = newTestOutlierDetector(single, OutlierDetection{Consecutive5xx: 1, MaxEjectionPercent: 10})
+	d.ObserveResponse(single[0], http.StatusInternalServerError)
+	if single[0].IsEjected() {
+		t.Error("唯一的后端被驱逐")
+	}
+}
+
+func TestOutlierEjectionBackoff(t *testing.T) {
+	const base, maxTime = time.Minute, 3 * time.Minute
+	backends := newTestBackends(t, 2)
+	b := backends[0]
+	d := newTestOutlierDetector(backends, OutlierDetection{
+		Consecutive5xx:     1,
+		BaseEjectionTime:   base,
+		MaxEjectionTime:    maxTime,
+		MaxEjectionPercent: 50,
+	})
+
+	// 驱逐时长为 基础时长×驱逐次数，不超过上限
+	for i, want := range []time.Duration{base, 2 * base, maxTime, maxTime} {
+		d.ObserveResponse(b, http.StatusInternalServerError)
+		if !b.IsEjected() {
+			t.Fatalf("第%d次驱逐未生效", i+1)
+		}
+		if got := time.Until(b.outlier.ejectedUntil); got > want || got < want-time.Second {
+			t.Fatalf("第%d次驱逐时长为 %v，期望 %v", i+1, got, want)
+		}
+
+		// 驱逐未到期时保持驱逐状态
+		d.evaluate()
+		if !b.IsEjected() {
+			t.Fatalf("第%d次驱逐未到期即恢复", i+1)
+		}
+
+		// 到期后恢复
+		b.outlier.ejectedUntil = time.Now().Add(-time.Second)
+		d.evaluate()
+		if b.IsEjected() {
+			t.Fatalf("第%d次驱逐到期后未恢复", i+1)
+		}
+	}
+	if got := b.OutlierEjections(); got != 4 {
+		t.Fatalf("驱逐次数为%d，期望4", got)
+	}
+
+	// 正常的周期内驱逐次数逐步衰减
+	d.evaluate()
+	d.evaluate()
+	if got := b.OutlierEjections(); got != 2 {
+		t.Errorf("两个正常周期后驱逐次数为%d，期望2", got)
+	}
+}
@@ -1,7 +1,6 @@
 package backend
 
 import (
-	"context"
 	"errors"
 	"log"
 	"sync"
@@ -15,11 +14,14 @@ type Pool struct {
 	retryBackends  []*Backend // 重试服务器
 	current        uint64
 	mux            sync.RWMutex
+	listeners      []func(active []*Backend) // 活跃池变化时的回调
+	maxFailures    int                       // 重试池中的后端连续失败该次数后进入隔离
+	quarantine     Quarantine                // 隔离后端的退避探测参数
 }
 
 // NewPool 创建新的后端服务器池
 func NewPool(backends []*Backend) *Pool {
-	p := &Pool{}
+	p := &Pool{maxFailures: DefaultMaxFailures, quarantine: Quarantine{}.withDefaults()}
 	for _, b := range backends {
 		if b.IsAlive() {
 			p.activeBackends = append(p.activeBackends, b)
@@ -54,13 +56,55 @@ func (p *Pool) GetNextPeer() (*Backend, error) {
 // AddBackend 添加新的后端到池中
 func (p *Pool) AddBackend(backend *Backend) {
 	p.mux.Lock()
-	defer p.mux.Unlock()
-
-	if backend.IsAlive() {
+	alive := backend.IsAlive()
+	if alive {
 		p.activeBackends = append(p.activeBackends, backend)
 	} else {
 		p.retryBackends = append(p.retryBackends, backend)
 	}
+	p.mux.Unlock()
+
+	if alive {
+		p.notifyChange()
+	}
+}
+
+// OnChange 注册活跃池变化时的回调，回调参数为变化后的活跃后端快照
+func (p *Pool) OnChange(fn func(active []*Backend)) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.listeners = append(p.listeners, fn)
+}
+
+// GetActiveBackends 获取活跃池中的后端快照，不含被离群检测驱逐或熔断的后端
+func (p *Pool) GetActiveBackends() []*Backend {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	active := make([]*Backend, 0, len(p.activeBackends))
+	for _, b := range p.activeBackends {
+		if !b.IsEjected() && b.CircuitState() != CircuitOpen {
+			active = append(active, b)
+		}
+	}
+	return active
+}
+
+// notifyChange 在不持有锁的情况下通知所有回调
+func (p *Pool) notifyChange() {
+	p.mux.RLock()
+	listeners := make([]func(active []*Backend), len(p.listeners))
+	copy(listeners, p.listeners)
+	p.mux.RUnlock()
+
+	if len(listeners) == 0 {
+		return
+	}
+
+	active := p.GetActiveBackends()
+	for _, fn := range listeners {
+		fn(active)
+	}
 }
 
 // GetBackends 获取所有后端（包括活跃和重试）
@@ -79,46 +123,56 @@ func (p *Pool) GetAll() []*Backend {
 	return p.GetBackends()
 }
 
-// MaxFailures 定义健康检查最大失败次数
-var MaxFailures = 3 // 默认值，会被配置文件覆盖
+// SetMaxFailures 设置重试池中的后端连续失败多少次后进入隔离，不大于0时使用默认值
+func (p *Pool) SetMaxFailures(n int) {
+	if n <= 0 {
+		n = DefaultMaxFailures
+	}
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.maxFailures = n
+}
 
-// HealthCheck 对所有后端执行健康检查
+// HealthCheck 对所有后端执行健康检查，隔离中的后端只在到达退避时间后探测
 func (p *Pool) HealthCheck(checker *HealthChecker) {
 	// 获取当前所有后端的快照，减少锁持有时间
-	p.mux.RLock()
-	activeBackendsCopy := make([]*Backend, len(p.activeBackends))
-	retryBackendsCopy := make([]*Backend, len(p.retryBackends))
-	copy(activeBackendsCopy, p.activeBackends)
-	copy(retryBackendsCopy, p.retryBackends)
-	p.mux.RUnlock()
+	now := time.Now()
+	backends := p.GetBackends()
+	due := make([]*Backend, 0, len(backends))
+	for _, b := range backends {
+		if b.status() != StatusFailed || b.quarantineDue(now) {
+			due = append(due, b)
+		}
+	}
+	p.check(checker, due)
+}
+
+// Recheck 立即探测指定后端并更新状态，忽略隔离的退避时间
+func (p *Pool) Recheck(checker *HealthChecker, backends []*Backend) {
+	for _, b := range backends {
+		b.clearQuarantineSchedule()
+	}
+	p.check(checker, backends)
+}
 
+// check 并行探测给定后端，并根据rise/fall阈值在活跃池、重试池和隔离之间转移
+func (p *Pool) check(checker *HealthChecker, backends []*Backend) {
 	// 用于收集检查结果
 	type checkResult struct {
-		backend *Backend
-		isAlive bool
+		backend   *Backend
+		successes int // 连续成功次数
+		failures  int // 连续失败次数
 	}
 
 	// 创建工作组进行并行健康检查
 	var wg sync.WaitGroup
-	resultChan := make(chan checkResult, len(activeBackendsCopy)+len(retryBackendsCopy))
-
-	// 检查活跃池中的服务器(并行)
-	for _, b := range activeBackendsCopy {
-		wg.Add(1)
-		go func(backend *Backend) {
-			defer wg.Done()
-			isAlive := p.checkBackend(backend, checker)
-			resultChan <- checkResult{backend: backend, isAlive: isAlive}
-		}(b)
-	}
-
-	// 检查重试池中的服务器(并行)
-	for _, b := range retryBackendsCopy {
+	resultChan := make(chan checkResult, len(backends))
+	for _, b := range backends {
 		wg.Add(1)
 		go func(backend *Backend) {
 			defer wg.Done()
-			isAlive := p.checkBackend(backend, checker)
-			resultChan <- checkResult{backend: backend, isAlive: isAlive}
+			successes, failures := p.checkBackend(backend, checker)
+			resultChan <- checkResult{backend: backend, successes: successes, failures: failures}
 		}(b)
 	}
 
@@ -128,86 +182,92 @@ func (p *Pool) HealthCheck(checker *HealthChecker) {
 		close(resultChan)
 	}()
 
-	// 收集所有结果
-	activeResults := make([]checkResult, 0)
-	retryResults := make([]checkResult, 0)
-
+	results := make([]checkResult, 0, len(backends))
 	for result := range resultChan {
-		// 根据backend当前所在池分类结果
-		isRetry := false
-		for _, b := range retryBackendsCopy {
-			if b == result.backend {
-				isRetry = true
-				break
-			}
-		}
-
-		if isRetry {
-			retryResults = append(retryResults, result)
-		} else {
-			activeResults = append(activeResults, result)
-		}
+		results = append(results, result)
 	}
 
-	// 一次性更新池状态，减少锁争用
+	// 一次性更新池状态，减少锁争用；按后端当前所在池分类，检查期间池可能已发生变化
+	changed := false
+	now := time.Now()
 	p.mux.Lock()
-	defer p.mux.Unlock()
+	for _, result := range results {
+		b := result.backend
+		rise, fall := b.healthThresholds()
 
-	// 处理活跃池结果
-	for _, result := range activeResults {
-		if !result.isAlive {
-			// 从活跃池移除
-			for i, b := range p.activeBackends {
-				if b == result.backend {
-					p.activeBackends = append(p.activeBackends[:i], p.activeBackends[i+1:]...)
-					break
-				}
+		if i := indexOf(p.activeBackends, b); i >= 0 {
+			// 连续失败达到fall次才移入重试池
+			if result.failures >= fall {
+				p.activeBackends = append(p.activeBackends[:i], p.activeBackends[i+1:]...)
+				b.SetStatus(StatusRetrying)
+				p.retryBackends = append(p.retryBackends, b)
+				changed = true
+				log.Printf("服务 %s 连续失败 %d 次，移入重试池", b.URL.Host, result.failures)
 			}
-			// 加入重试池
-			result.backend.SetStatus(StatusRetrying)
-			p.retryBackends = append(p.retryBackends, result.backend)
-			log.Printf("服务 %s 移入重试池", result.backend.URL.Host)
+			continue
 		}
-	}
 
-	// 处理重试池结果
-	for _, result := range retryResults {
-		if result.isAlive {
-			// 从重试池移除
-			for i, b := range p.retryBackends {
-				if b == result.backend {
-					p.retryBackends = append(p.retryBackends[:i], p.retryBackends[i+1:]...)
-					break
-				}
-			}
-			// 加入活跃池
-			result.backend.SetStatus(StatusActive)
-			p.activeBackends = append(p.activeBackends, result.backend)
-			log.Printf("服务 %s 恢复并移入活跃池", result.backend.URL.Host)
-		} else if result.backend.FailureCount >= MaxFailures { // 修改为直接使用MaxFailures而不是*2
-			// 彻底移除
-			for i, b := range p.retryBackends {
-				if b == result.backend {
-					p.retryBackends = append(p.retryBackends[:i], p.retryBackends[i+1:]...)
-					break
-				}
+		i := indexOf(p.retryBackends, b)
+		if i < 0 {
+			continue
+		}
+		switch {
+		case result.successes >= rise:
+			// 连续成功达到rise次才恢复
+			p.retryBackends = append(p.retryBackends[:i], p.retryBackends[i+1:]...)
+			b.SetStatus(StatusActive)
+			p.activeBackends = append(p.activeBackends, b)
+			changed = true
+			log.Printf("服务 %s 连续成功 %d 次，恢复并移入活跃池", b.URL.Host, result.successes)
+		case b.status() == StatusFailed:
+			// 隔离中探测成功但未达到rise次时下一轮继续探测，失败则按指数退避
+			if result.successes > 0 {
+				b.clearQuarantineSchedule()
+			} else {
+				d := b.scheduleQuarantine(p.quarantine, now)
+				log.Printf("隔离中的服务 %s 探测失败，%s后重新探测", b.URL.Host, d)
 			}
-			log.Printf("服务 %s 连续失败 %d 次，已从池中移除", result.backend.URL.Host, result.backend.FailureCount)
+		case result.failures >= p.maxFailures:
+			b.SetStatus(StatusFailed)
+			d := b.scheduleQuarantine(p.quarantine, now)
+			log.Printf("服务 %s 连续失败 %d 次，进入隔离，%s后重新探测", b.URL.Host, result.failures, d)
 		}
 	}
-}
+	p.mux.Unlock()
 
-// checkBackend 执行健康检查并更新状态
-func (p *Pool) checkBackend(b *Backend, checker *HealthChecker) bool {
-	// 设置单个后端的健康检查超时时间
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	// 活跃池发生变化时通知订阅者(如需要重建查找表的算法)
+	if changed {
+		p.notifyChange()
+	}
+}
 
-	// 使用通道来实现超时控制
-	resultChan := make(chan bool, 1)
+// indexOf 返回后端在列表中的位置，不存在时返回-1
+func indexOf(backends []*Backend, b *Backend) int {
+	for i, x := range backends {
+		if x == b {
+			return i
+		}
+	}
+	return -1
+}
 
-	go func() {
-		var isAlive bool
+// checkBackend 执行健康检查并返回后端的连续成功和失败次数
+func (p *Pool) checkBackend(b *Backend, checker *HealthChecker) (successes, failures int) {
+	probe := b.healthProbe()
+	isAlive := checker.performCheckWithRetry(func() bool {
+		// 配置了探测方式时按其定义检查
+		if probe != nil {
+			ctx, cancel := checker.probeContext()
+			defer cancel()
+			detail, err := runProbe(ctx, probe, b)
+			if err != nil {
+				log.Printf("健康检查 - %s: %v", b.URL.Host, err)
+				b.setHealthDetail(err.Error())
+				return false
+			}
+			b.setHealthDetail(detail)
+			return true
+		}
 
 		// 根据配置选择检查方式
 		path := b.HealthCheckPath
@@ -215,31 +275,13 @@ func (p *Pool) checkBackend(b *Backend, checker *HealthChecker) bool {
 			// HTTP检查
 			url := b.URL.String() + path
 			log.Printf("健康检查 - URL: %s", url)
-			isAlive = checker.checkHTTP(url)
-		} else {
-			// TCP检查
-			addr := b.URL.Host
-			log.Printf("健康检查 - 地址: %s", addr)
-			isAlive = checker.checkTCP(addr)
+			return checker.checkHTTP(url)
 		}
 
-		resultChan <- isAlive
-	}()
-
-	// 等待结果或超时
-	select {
-	case isAlive := <-resultChan:
-		if isAlive {
-			b.SetStatus(StatusActive)
-			b.FailureCount = 0
-		} else {
-			b.FailureCount++
-		}
-		return isAlive
-	case <-ctx.Done():
-		// 健康检查超时，视为失败
-		log.Printf("健康检查超时: %s", b.URL.Host)
-		b.FailureCount++
-		return false
-	}
+		// TCP检查
+		addr := b.URL.Host
+		log.Printf("健康检查 - 地址: %s", addr)
+		return checker.checkTCP(addr)
+	})
+	return b.recordCheck(isAlive)
 }
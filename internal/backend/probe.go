@@ -0,0 +1,110 @@
+package backend
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// Probe 对单个后端执行一次健康探测，返回nil表示健康
+type Probe interface {
+	Check(ctx context.Context, b *Backend) error
+}
+
+// DetailedProbe 可以在探测成功时返回详情的健康探测，如脚本检查的输出
+type DetailedProbe interface {
+	Probe
+	CheckDetail(ctx context.Context, b *Backend) (string, error)
+}
+
+// runProbe 执行一次探测，返回记录到后端健康状态中的详情
+func runProbe(ctx context.Context, p Probe, b *Backend) (string, error) {
+	if dp, ok := p.(DetailedProbe); ok {
+		return dp.CheckDetail(ctx, b)
+	}
+	return "", p.Check(ctx, b)
+}
+
+// HealthDetail 返回最近一次健康探测的详情，失败时为失败原因
+func (b *Backend) HealthDetail() string {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+	return b.health.detail
+}
+
+// setHealthDetail 记录健康探测的详情
+func (b *Backend) setHealthDetail(detail string) {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+	b.health.detail = detail
+}
+
+// SetProbe 设置后端的健康探测方式，为nil时按HealthCheckPath执行HTTP或TCP检查
+func (b *Backend) SetProbe(p Probe) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.probe = p
+}
+
+// healthProbe 返回后端的健康探测方式
+func (b *Backend) healthProbe() Probe {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	return b.probe
+}
+
+// probeAddr 返回健康探测的目标地址，port不大于0时使用流量端口
+func probeAddr(b *Backend, port int) string {
+	if port <= 0 {
+		if b.URL.Port() != "" {
+			return b.URL.Host
+		}
+		port = 80
+		if b.URL.Scheme == "https" {
+			port = 443
+		}
+	}
+	return net.JoinHostPort(b.URL.Hostname(), strconv.Itoa(port))
+}
+
+// ProbeTLS 健康探测的TLS配置
+type ProbeTLS struct {
+	InsecureSkipVerify bool   // 跳过证书校验
+	ServerName         string // SNI及证书校验使用的主机名，为空时使用后端主机名
+	CAFile             string // 校验服务端证书的CA文件，为空时使用系统CA
+	CertFile           string // 客户端证书，用于双向TLS
+	KeyFile            string
+}
+
+// config 生成tls.Config
+func (t ProbeTLS) config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		ServerName:         t.ServerName,
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA文件失败: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA文件 %s 中没有有效的证书", t.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,87 @@
+package backend
+
+import "time"
+
+// 隔离退避的默认值
+const (
+	DefaultQuarantineBackoff    = 10 * time.Second
+	DefaultQuarantineMaxBackoff = 5 * time.Minute
+)
+
+// Quarantine 隔离配置：连续失败达到上限的后端不再每轮探测，
+// 第n次探测失败后等待 Backoff×2^(n-1) 再探测，不超过MaxBackoff
+type Quarantine struct {
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// quarantineState 后端的隔离状态，由healthCounter的锁保护
+type quarantineState struct {
+	failures  int       // 隔离期间的探测失败次数
+	nextCheck time.Time // 下次探测时间，零值表示下一轮即探测
+}
+
+// withDefaults 返回填充默认值后的隔离配置
+func (q Quarantine) withDefaults() Quarantine {
+	if q.Backoff <= 0 {
+		q.Backoff = DefaultQuarantineBackoff
+	}
+	if q.MaxBackoff <= 0 {
+		q.MaxBackoff = DefaultQuarantineMaxBackoff
+	}
+	if q.MaxBackoff < q.Backoff {
+		q.MaxBackoff = q.Backoff
+	}
+	return q
+}
+
+// delay 返回隔离期间第n次探测失败后的等待时间
+func (q Quarantine) delay(n int) time.Duration {
+	d := q.Backoff
+	for i := 1; i < n && d < q.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > q.MaxBackoff {
+		d = q.MaxBackoff
+	}
+	return d
+}
+
+// SetQuarantine 设置隔离退避参数，不大于0的字段使用默认值
+func (p *Pool) SetQuarantine(q Quarantine) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.quarantine = q.withDefaults()
+}
+
+// scheduleQuarantine 记录一次隔离期间的探测失败并安排下次探测，返回等待时间
+func (b *Backend) scheduleQuarantine(q Quarantine, now time.Time) time.Duration {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+
+	b.health.quarantine.failures++
+	d := q.delay(b.health.quarantine.failures)
+	b.health.quarantine.nextCheck = now.Add(d)
+	return d
+}
+
+// quarantineDue 返回后端是否到了探测时间
+func (b *Backend) quarantineDue(now time.Time) bool {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+	return !now.Before(b.health.quarantine.nextCheck)
+}
+
+// clearQuarantineSchedule 取消等待，使后端在下一轮即被探测，不影响退避次数
+func (b *Backend) clearQuarantineSchedule() {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+	b.health.quarantine.nextCheck = time.Time{}
+}
+
+// QuarantinedUntil 返回被隔离的后端下次探测的时间，未在等待时为零值
+func (b *Backend) QuarantinedUntil() time.Time {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+	return b.health.quarantine.nextCheck
+}
@@ -0,0 +1,154 @@
+package backend
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuarantineDelay(t *testing.T) {
+	q := Quarantine{Backoff: 10 * time.Second, MaxBackoff: 5 * time.Minute}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{5, 160 * time.Second},
+		{6, 5 * time.Minute},
+		{100, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := q.delay(tt.failures); got != tt.want {
+			t.Errorf("第%d次失败后等待 %v，期望 %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestQuarantineDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		q    Quarantine
+		want Quarantine
+	}{
+		{"零值", Quarantine{}, Quarantine{DefaultQuarantineBackoff, DefaultQuarantineMaxBackoff}},
+		{"上限小于起始值", Quarantine{Backoff: time.Minute, MaxBackoff: time.Second}, Quarantine{time.Minute, time.Minute}},
+	}
+	for _, tt := range tests {
+		if got := tt.q.withDefaults(); got != tt.want {
+			t.Errorf("%s: 得到 %+v，期望 %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// expireQuarantine 使被隔离的后端立即到达探测时间
+func expireQuarantine(b *Backend) {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+	b.health.quarantine.nextCheck = time.Now().Add(-time.Second)
+}
+
+// assertQuarantinedFor 断言后端下次探测时间约为当前时间之后d
+func assertQuarantinedFor(t *testing.T, b *Backend, d time.Duration) {
+	t.Helper()
+	if got := time.Until(b.QuarantinedUntil()); got > d || got < d-time.Second {
+		t.Fatalf("距下次探测 %v，期望 %v", got, d)
+	}
+}
+
+func TestQuarantineBackoffGrowsAndCaps(t *testing.T) {
+	b := newTestBackend(t)
+	b.SetAlive(false)
+	probe := &stubProbe{}
+	b.SetProbe(probe)
+	pool, checker := newTestChecker([]*Backend{b})
+	pool.SetMaxFailures(2)
+	pool.SetQuarantine(Quarantine{Backoff: time.Minute, MaxBackoff: 3 * time.Minute})
+
+	// 重试池中连续失败达到上限后进入隔离
+	pool.HealthCheck(checker)
+	if b.GetStatus() != StatusRetrying {
+		t.Fatalf("失败1次后状态为 %s，期望 %s", b.GetStatus(), StatusRetrying)
+	}
+	pool.HealthCheck(checker)
+	if b.GetStatus() != StatusFailed {
+		t.Fatalf("失败2次后状态为 %s，期望 %s", b.GetStatus(), StatusFailed)
+	}
+	assertQuarantinedFor(t, b, time.Minute)
+
+	// 退避期间不探测
+	checks := probe.checks.Load()
+	pool.HealthCheck(checker)
+	if probe.checks.Load() != checks {
+		t.Fatal("隔离的后端在退避期间被探测")
+	}
+
+	// 每次探测失败后等待时间翻倍，不超过上限
+	for _, want := range []time.Duration{2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		expireQuarantine(b)
+		pool.HealthCheck(checker)
+		if probe.checks.Load() != checks+1 {
+			t.Fatal("到达探测时间后未探测")
+		}
+		checks++
+		assertQuarantinedFor(t, b, want)
+	}
+
+	// 探测成功后恢复并清空退避
+	expireQuarantine(b)
+	probe.healthy.Store(true)
+	pool.HealthCheck(checker)
+	if !b.IsAlive() {
+		t.Fatalf("探测成功后状态为 %s，期望恢复", b.GetStatus())
+	}
+	if !b.QuarantinedUntil().IsZero() {
+		t.Error("恢复后仍有隔离退避")
+	}
+
+	// 再次进入隔离时退避从起始值重新开始
+	probe.healthy.Store(false)
+	for i := 0; i < 3; i++ {
+		pool.HealthCheck(checker)
+	}
+	if b.GetStatus() != StatusFailed {
+		t.Fatalf("再次连续失败后状态为 %s，期望 %s", b.GetStatus(), StatusFailed)
+	}
+	assertQuarantinedFor(t, b, time.Minute)
+}
+
+func TestRecheckIgnoresQuarantineBackoff(t *testing.T) {
+	b := newTestBackend(t)
+	b.SetAlive(false)
+	probe := &stubProbe{}
+	b.SetProbe(probe)
+	pool, checker := newTestChecker([]*Backend{b})
+	pool.SetMaxFailures(1)
+	pool.SetQuarantine(Quarantine{Backoff: time.Hour, MaxBackoff: 4 * time.Hour})
+
+	pool.HealthCheck(checker)
+	if b.GetStatus() != StatusFailed {
+		t.Fatalf("状态为 %s，期望 %s", b.GetStatus(), StatusFailed)
+	}
+
+	// 管理员重新检查时立即探测，失败则继续退避
+	checked, err := checker.Recheck("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checked) != 1 || probe.checks.Load() != 2 {
+		t.Fatalf("重新检查了%d个后端、共探测%d次，期望立即探测被隔离的后端", len(checked), probe.checks.Load())
+	}
+	assertQuarantinedFor(t, b, 2*time.Hour)
+
+	probe.healthy.Store(true)
+	if _, err := checker.Recheck(b.URL.Host); err != nil {
+		t.Fatal(err)
+	}
+	if !b.IsAlive() {
+		t.Errorf("重新检查成功后状态为 %s，期望恢复", b.GetStatus())
+	}
+
+	if _, err := checker.Recheck("http://10.9.9.9:8080"); err == nil {
+		t.Error("重新检查不存在的后端未返回错误")
+	}
+}
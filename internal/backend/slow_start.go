@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"math"
+	"time"
+)
+
+// 慢启动权重增长方式
+const (
+	SlowStartLinear      = "linear"
+	SlowStartExponential = "exponential"
+)
+
+// DefaultSlowStartMinRatio 慢启动开始时有效权重占配置权重的默认比例
+const DefaultSlowStartMinRatio = 0.1
+
+// SlowStart 慢启动配置：后端恢复后在Duration内将有效权重从MinRatio逐渐提升到完整权重
+type SlowStart struct {
+	Duration time.Duration
+	Mode     string  // linear 或 exponential
+	MinRatio float64 // 起始比例，取值(0,1]
+}
+
+// SetSlowStart 设置后端的慢启动参数
+func (b *Backend) SetSlowStart(ss SlowStart) {
+	if ss.MinRatio <= 0 || ss.MinRatio > 1 {
+		ss.MinRatio = DefaultSlowStartMinRatio
+	}
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.slowStart = ss
+}
+
+// SlowStartFactor 返回当前慢启动系数，取值(0,1]，不在慢启动期间时为1
+func (b *Backend) SlowStartFactor() float64 {
+	b.mux.RLock()
+	ss, since := b.slowStart, b.activeSince
+	b.mux.RUnlock()
+
+	if ss.Duration <= 0 || since.IsZero() {
+		return 1
+	}
+	elapsed := time.Since(since)
+	if elapsed >= ss.Duration {
+		return 1
+	}
+
+	progress := float64(elapsed) / float64(ss.Duration)
+	if ss.Mode == SlowStartExponential {
+		// 从MinRatio按指数增长到1
+		return ss.MinRatio * math.Pow(1/ss.MinRatio, progress)
+	}
+	return ss.MinRatio + (1-ss.MinRatio)*progress
+}
@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSlowStartFactor(t *testing.T) {
+	const duration = time.Hour
+	tests := []struct {
+		name     string
+		mode     string
+		minRatio float64
+		elapsed  time.Duration // 距离恢复的时间，为负数表示从未恢复过
+		want     float64
+	}{
+		{"线性-刚恢复", SlowStartLinear, 0.1, 0, 0.1},
+		{"线性-四分之一", SlowStartLinear, 0.1, duration / 4, 0.325},
+		{"线性-一半", SlowStartLinear, 0.2, duration / 2, 0.6},
+		{"线性-结束", SlowStartLinear, 0.1, duration, 1},
+		{"线性-结束之后", SlowStartLinear, 0.1, 2 * duration, 1},
+		{"指数-刚恢复", SlowStartExponential, 0.1, 0, 0.1},
+		{"指数-一半", SlowStartExponential, 0.04, duration / 2, 0.2},
+		{"指数-四分之三", SlowStartExponential, 0.0625, duration * 3 / 4, 0.5},
+		{"指数-结束", SlowStartExponential, 0.1, duration, 1},
+		{"未指定模式按线性", "", 0.5, duration / 2, 0.75},
+		{"最小比例为0时使用默认值", SlowStartLinear, 0, 0, DefaultSlowStartMinRatio},
+		{"最小比例为负数时使用默认值", SlowStartExponential, -1, 0, DefaultSlowStartMinRatio},
+		{"最小比例大于1时使用默认值", SlowStartLinear, 1.5, 0, DefaultSlowStartMinRatio},
+		{"最小比例为1时不降低权重", SlowStartExponential, 1, 0, 1},
+		{"从未恢复过", SlowStartLinear, 0.1, -1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBackend(t)
+			b.SetSlowStart(SlowStart{Duration: duration, Mode: tt.mode, MinRatio: tt.minRatio})
+			if tt.elapsed >= 0 {
+				b.activeSince = time.Now().Add(-tt.elapsed)
+			}
+			if got := b.SlowStartFactor(); math.Abs(got-tt.want) > 1e-3 {
+				t.Errorf("慢启动系数为 %.4f，期望 %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlowStartDisabled(t *testing.T) {
+	b := newTestBackend(t)
+	b.activeSince = time.Now()
+	if got := b.SlowStartFactor(); got != 1 {
+		t.Errorf("未配置慢启动时系数为 %v，期望 1", got)
+	}
+}
+
+func TestSlowStartResetsWhenBackendRecovers(t *testing.T) {
+	b := newTestBackend(t)
+	b.SetSlowStart(SlowStart{Duration: time.Hour, Mode: SlowStartLinear, MinRatio: 0.1})
+
+	// 启动时即为活跃状态的后端不进入慢启动
+	if got := b.SlowStartFactor(); got != 1 {
+		t.Fatalf("初始活跃的后端慢启动系数为 %v，期望 1", got)
+	}
+
+	b.SetAlive(false)
+	b.SetAlive(true)
+	if got := b.SlowStartFactor(); math.Abs(got-0.1) > 1e-3 {
+		t.Fatalf("恢复后慢启动系数为 %.4f，期望从 0.1 开始", got)
+	}
+	if got := b.EffectiveWeight(); got > 0.2 {
+		t.Errorf("恢复后有效权重为 %.4f，期望按慢启动系数降低", got)
+	}
+
+	// 慢启动结束后再次恢复，重新从最小比例开始
+	b.activeSince = time.Now().Add(-2 * time.Hour)
+	if got := b.SlowStartFactor(); got != 1 {
+		t.Fatalf("慢启动结束后系数为 %v，期望 1", got)
+	}
+	b.SetAlive(false)
+	b.SetAlive(true)
+	if got := b.SlowStartFactor(); math.Abs(got-0.1) > 1e-3 {
+		t.Errorf("再次恢复后慢启动系数为 %.4f，期望重新从 0.1 开始", got)
+	}
+
+	// 已经活跃时重复设置为活跃不会重置慢启动
+	b.activeSince = time.Now().Add(-30 * time.Minute)
+	b.SetAlive(true)
+	if got := b.SlowStartFactor(); math.Abs(got-0.55) > 1e-3 {
+		t.Errorf("重复设置为活跃后系数为 %.4f，期望保持 0.55", got)
+	}
+}
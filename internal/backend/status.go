@@ -1,7 +1,9 @@
 package backend
 
 const (
-	StatusActive   = "active"
-	StatusRetrying = "retrying"
-	StatusFailed   = "failed"
+	StatusActive      = "active"
+	StatusRetrying    = "retrying"
+	StatusFailed      = "failed"
+	StatusEjected     = "ejected"      // 被离群检测驱逐，仅用于展示
+	StatusCircuitOpen = "circuit_open" // 熔断器打开，仅用于展示
 )
@@ -0,0 +1,134 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+)
+
+// TCPCheck TCP健康检查定义：建立连接后可发送数据并校验回复
+type TCPCheck struct {
+	Port        int      // 检查端口，不大于0时使用流量端口
+	Send        []byte   // 连接建立后发送的数据，为空时不发送
+	Expect      []byte   // 回复需以此为前缀
+	ExpectRegex string   // 回复需匹配的正则表达式
+	UseTLS      bool     // 使用TLS连接
+	TLS         ProbeTLS // UseTLS时的TLS配置
+}
+
+// tcpProbe 按TCPCheck定义执行TCP健康检查
+type tcpProbe struct {
+	check TCPCheck
+	regex *regexp.Regexp
+	tls   *tls.Config
+}
+
+// NewTCPProbe 创建TCP健康探测
+func NewTCPProbe(c TCPCheck) (Probe, error) {
+	p := &tcpProbe{check: c}
+	if c.ExpectRegex != "" {
+		regex, err := regexp.Compile(c.ExpectRegex)
+		if err != nil {
+			return nil, fmt.Errorf("无效的回复正则表达式: %v", err)
+		}
+		p.regex = regex
+	}
+	if c.UseTLS {
+		tlsConfig, err := c.TLS.config()
+		if err != nil {
+			return nil, err
+		}
+		p.tls = tlsConfig
+	}
+	return p, nil
+}
+
+// Check 实现Probe接口
+func (p *tcpProbe) Check(ctx context.Context, b *Backend) error {
+	addr := probeAddr(b, p.check.Port)
+
+	var conn net.Conn
+	var err error
+	if p.tls != nil {
+		dialer := &tls.Dialer{Config: p.tls}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if len(p.check.Send) > 0 {
+		if _, err := conn.Write(p.check.Send); err != nil {
+			return fmt.Errorf("发送数据失败: %v", err)
+		}
+	}
+	if len(p.check.Expect) == 0 && p.regex == nil {
+		return nil
+	}
+	return p.expect(conn)
+}
+
+// expect 读取回复直到满足期望、连接关闭或超过读取上限
+func (p *tcpProbe) expect(conn net.Conn) error {
+	var reply []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		reply = append(reply, buf[:n]...)
+		if p.matched(reply) {
+			return nil
+		}
+		if p.mismatched(reply) {
+			return fmt.Errorf("回复 %q 与期望不符", truncate(reply))
+		}
+
+		if err != nil {
+			// 已收到部分回复时(包括超时)报告回复内容，便于排查
+			if len(reply) > 0 || errors.Is(err, io.EOF) {
+				return fmt.Errorf("回复 %q 与期望不符", truncate(reply))
+			}
+			return fmt.Errorf("读取回复失败: %v", err)
+		}
+		if len(reply) >= maxProbeBody {
+			return fmt.Errorf("回复 %q 与期望不符", truncate(reply))
+		}
+	}
+}
+
+// matched 判断已读取的回复是否满足所有期望
+func (p *tcpProbe) matched(reply []byte) bool {
+	expect := p.check.Expect
+	if len(expect) > 0 && !bytes.HasPrefix(reply, expect) {
+		return false
+	}
+	return p.regex == nil || p.regex.Match(reply)
+}
+
+// mismatched 判断已读取的回复是否已经不可能满足前缀期望
+func (p *tcpProbe) mismatched(reply []byte) bool {
+	expect := p.check.Expect
+	n := min(len(reply), len(expect))
+	return !bytes.Equal(reply[:n], expect[:n])
+}
+
+// truncate 截断过长的回复用于日志
+func truncate(reply []byte) []byte {
+	const limit = 64
+	if len(reply) > limit {
+		return reply[:limit]
+	}
+	return reply
+}
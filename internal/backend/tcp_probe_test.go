@@ -0,0 +1,185 @@
+package backend
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tcpServer 启动TCP测试服务器，每个连接交给handle处理，返回指向它的后端
+func tcpServer(t *testing.T, handle func(conn net.Conn)) *Backend {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return probeBackend(t, "http://"+ln.Addr().String())
+}
+
+// runTCPProbe 创建TCP探测并在timeout内对后端执行一次检查
+func runTCPProbe(t *testing.T, c TCPCheck, b *Backend, timeout time.Duration) error {
+	t.Helper()
+	p, err := NewTCPProbe(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return p.Check(ctx, b)
+}
+
+func TestTCPProbeSendExpect(t *testing.T) {
+	// 收到一行命令后分两次写回复，校验探测会拼接分段的回复
+	b := tcpServer(t, func(conn net.Conn) {
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+		if line != "PING\r\n" {
+			conn.Write([]byte("-ERR unknown command\r\n"))
+			return
+		}
+		conn.Write([]byte("+PO"))
+		time.Sleep(10 * time.Millisecond)
+		conn.Write([]byte("NG role:master\r\n"))
+	})
+
+	ping := []byte("PING\r\n")
+	tests := []struct {
+		name  string
+		check TCPCheck
+		ok    bool
+	}{
+		{"只建立连接", TCPCheck{}, true},
+		{"只发送不校验", TCPCheck{Send: ping}, true},
+		{"前缀匹配", TCPCheck{Send: ping, Expect: []byte("+PONG")}, true},
+		{"前缀不匹配", TCPCheck{Send: ping, Expect: []byte("+OK")}, false},
+		{"服务端返回错误", TCPCheck{Send: []byte("INFO\r\n"), Expect: []byte("+PONG")}, false},
+		{"正则匹配", TCPCheck{Send: ping, ExpectRegex: `role:(master|replica)`}, true},
+		{"正则不匹配", TCPCheck{Send: ping, ExpectRegex: `role:replica`}, false},
+		{"前缀和正则同时满足", TCPCheck{Send: ping, Expect: []byte("+PONG"), ExpectRegex: `master\r\n$`}, true},
+		{"前缀满足但正则不满足", TCPCheck{Send: ping, Expect: []byte("+PONG"), ExpectRegex: `replica`}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runTCPProbe(t, tt.check, b, 2*time.Second)
+			if (err == nil) != tt.ok {
+				t.Errorf("探测结果为 %v，期望成功为 %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTCPProbeMismatchFailsFast(t *testing.T) {
+	// 回复前缀已不符时应立即失败，不等待连接关闭或超时
+	b := tcpServer(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-2.0-OpenSSH\r\n"))
+		time.Sleep(2 * time.Second)
+	})
+
+	start := time.Now()
+	err := runTCPProbe(t, TCPCheck{Expect: []byte("220 ")}, b, 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "SSH-2.0") {
+		t.Errorf("探测返回 %v，期望报告不符的回复", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("前缀不符后等待了 %v", elapsed)
+	}
+}
+
+func TestTCPProbeTimeout(t *testing.T) {
+	// 服务端不回复时，探测应在上下文超时后返回
+	b := tcpServer(t, func(conn net.Conn) {
+		time.Sleep(2 * time.Second)
+	})
+
+	start := time.Now()
+	err := runTCPProbe(t, TCPCheck{Send: []byte("PING\r\n"), Expect: []byte("+PONG")}, b, 100*time.Millisecond)
+	if err == nil {
+		t.Fatal("服务端未回复时探测应失败")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("超时为100ms，探测耗时 %v", elapsed)
+	}
+}
+
+func TestTCPProbeConnectionFailures(t *testing.T) {
+	// 连接后立即关闭，且未收到期望的回复
+	closed := tcpServer(t, func(conn net.Conn) {})
+	if err := runTCPProbe(t, TCPCheck{Expect: []byte("+OK")}, closed, 2*time.Second); err == nil {
+		t.Error("连接关闭且无回复时探测应失败")
+	}
+
+	refused := probeBackend(t, "http://"+closedAddr(t))
+	if err := runTCPProbe(t, TCPCheck{}, refused, 2*time.Second); err == nil {
+		t.Error("连接被拒绝时探测应失败")
+	}
+}
+
+func TestTCPProbePort(t *testing.T) {
+	// 后端流量端口不可用，通过Port指定检查端口
+	b := tcpServer(t, func(conn net.Conn) {})
+	_, port, _ := net.SplitHostPort(b.URL.Host)
+	traffic := probeBackend(t, "http://"+closedAddr(t))
+	if err := runTCPProbe(t, TCPCheck{Port: atoi(t, port)}, traffic, 2*time.Second); err != nil {
+		t.Errorf("指定检查端口后探测失败: %v", err)
+	}
+}
+
+func TestTCPProbeTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	b := probeBackend(t, srv.URL)
+
+	check := TCPCheck{
+		Send:   []byte("GET / HTTP/1.0\r\n\r\n"),
+		Expect: []byte("HTTP/1.0 200"),
+		UseTLS: true,
+		TLS:    ProbeTLS{InsecureSkipVerify: true},
+	}
+	if err := runTCPProbe(t, check, b, 2*time.Second); err != nil {
+		t.Errorf("TLS探测失败: %v", err)
+	}
+
+	check.TLS = ProbeTLS{}
+	if err := runTCPProbe(t, check, b, 2*time.Second); err == nil {
+		t.Error("不信任的证书应导致TLS探测失败")
+	}
+}
+
+func TestNewTCPProbeInvalidRegex(t *testing.T) {
+	if _, err := NewTCPProbe(TCPCheck{ExpectRegex: "("}); err == nil {
+		t.Error("无效的正则表达式未返回错误")
+	}
+}
+
+// closedAddr 返回一个当前没有监听的本地地址
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
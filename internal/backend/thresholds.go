@@ -0,0 +1,81 @@
+package backend
+
+import "sync"
+
+// 健康检查阈值的默认值
+const (
+	DefaultRise        = 1 // 连续成功该次数后标记为可用
+	DefaultFall        = 1 // 连续失败该次数后标记为不可用
+	DefaultMaxFailures = 3 // 重试池中的后端连续失败该次数后进入隔离
+)
+
+// healthCounter 后端健康检查的连续成功/失败计数
+type healthCounter struct {
+	mu         sync.Mutex
+	rise       int
+	fall       int
+	successes  int
+	failures   int
+	quarantine quarantineState
+	detail     string // 最近一次探测的详情
+}
+
+// SetHealthThresholds 设置后端的rise/fall阈值，不大于0时使用默认值
+func (b *Backend) SetHealthThresholds(rise, fall int) {
+	if rise <= 0 {
+		rise = DefaultRise
+	}
+	if fall <= 0 {
+		fall = DefaultFall
+	}
+
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+	b.health.rise = rise
+	b.health.fall = fall
+}
+
+// healthThresholds 返回后端的rise/fall阈值
+func (b *Backend) healthThresholds() (rise, fall int) {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+
+	rise, fall = b.health.rise, b.health.fall
+	if rise <= 0 {
+		rise = DefaultRise
+	}
+	if fall <= 0 {
+		fall = DefaultFall
+	}
+	return rise, fall
+}
+
+// recordCheck 记录一次健康检查结果，返回连续成功次数和连续失败次数
+func (b *Backend) recordCheck(ok bool) (successes, failures int) {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+
+	if ok {
+		b.health.successes++
+		b.health.failures = 0
+	} else {
+		b.health.failures++
+		b.health.successes = 0
+	}
+	return b.health.successes, b.health.failures
+}
+
+// FailureCount 返回健康检查的连续失败次数
+func (b *Backend) FailureCount() int {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+	return b.health.failures
+}
+
+// resetHealthCounter 清零健康检查的连续失败计数和隔离状态
+func (b *Backend) resetHealthCounter() {
+	b.health.mu.Lock()
+	defer b.health.mu.Unlock()
+	b.health.failures = 0
+	b.health.quarantine = quarantineState{}
+}
@@ -0,0 +1,85 @@
+package backend
+
+import (
+	"testing"
+)
+
+// isActive 返回后端是否在活跃池中
+func isActive(pool *Pool, b *Backend) bool {
+	return indexOf(pool.GetActiveBackends(), b) >= 0
+}
+
+// runChecks 按给定结果依次执行健康检查，返回每轮检查后后端是否在活跃池中
+func runChecks(pool *Pool, checker *HealthChecker, probe *stubProbe, b *Backend, results []bool) []bool {
+	active := make([]bool, len(results))
+	for i, healthy := range results {
+		probe.healthy.Store(healthy)
+		pool.HealthCheck(checker)
+		active[i] = isActive(pool, b)
+	}
+	return active
+}
+
+func TestHealthThresholds(t *testing.T) {
+	tests := []struct {
+		name       string
+		rise, fall int
+		startAlive bool
+		results    []bool // 每轮探测结果
+		want       []bool // 每轮检查后是否在活跃池中
+	}{
+		{"连续失败达到fall次才下线", 1, 3, true,
+			[]bool{false, false, false}, []bool{true, true, false}},
+		{"失败计数被成功打断", 1, 3, true,
+			[]bool{false, false, true, false, false, false}, []bool{true, true, true, true, true, false}},
+		{"连续成功达到rise次才上线", 3, 1, false,
+			[]bool{true, true, true}, []bool{false, false, true}},
+		{"成功计数被失败打断", 2, 1, false,
+			[]bool{true, false, true, true}, []bool{false, false, false, true}},
+		{"默认阈值为1", 0, 0, true,
+			[]bool{false, true}, []bool{false, true}},
+		{"上线后需要重新累计fall次失败", 2, 2, false,
+			[]bool{true, true, false, true, false, false}, []bool{false, true, true, true, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBackend(t)
+			b.SetAlive(tt.startAlive)
+			b.SetHealthThresholds(tt.rise, tt.fall)
+			probe := &stubProbe{}
+			b.SetProbe(probe)
+			pool, checker := newTestChecker([]*Backend{b})
+
+			got := runChecks(pool, checker, probe, b, tt.results)
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("第%d轮检查后是否活跃为 %v，期望 %v (全部结果 %v)", i+1, got[i], tt.want[i], got)
+				}
+			}
+			if alive := b.IsAlive(); alive != tt.want[len(tt.want)-1] {
+				t.Errorf("后端存活状态为 %v，与所在的池不一致", alive)
+			}
+		})
+	}
+}
+
+func TestHealthThresholdsPerBackend(t *testing.T) {
+	backends := newTestBackends(t, 2)
+	strict, lenient := backends[0], backends[1]
+	strict.SetHealthThresholds(1, 1)
+	lenient.SetHealthThresholds(1, 3)
+	probe := &stubProbe{}
+	for _, b := range backends {
+		b.SetProbe(probe)
+	}
+	pool, checker := newTestChecker(backends)
+
+	pool.HealthCheck(checker)
+	if isActive(pool, strict) {
+		t.Error("fall为1的后端失败一次后仍在活跃池中")
+	}
+	if !isActive(pool, lenient) {
+		t.Error("fall为3的后端失败一次后被移出活跃池")
+	}
+}
@@ -0,0 +1,216 @@
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// contextKey 客户端IP在请求上下文中的键
+type contextKey struct{}
+
+// 受信任代理传递客户端地址使用的转发头
+const (
+	// HeaderXFF 使用X-Forwarded-For
+	HeaderXFF = "xff"
+	// HeaderForwarded 使用RFC 7239 Forwarded头的for参数
+	HeaderForwarded = "forwarded"
+)
+
+// PrefixList CIDR网段列表
+type PrefixList []netip.Prefix
+
+// ParsePrefixes 解析CIDR或单个IP地址列表，空字符串被忽略
+func ParsePrefixes(specs []string) (PrefixList, error) {
+	var list PrefixList
+	for _, s := range specs {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("无效的IP地址: %s", s)
+			}
+			list = append(list, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("无效的网段: %s", s)
+		}
+		list = append(list, prefix.Masked())
+	}
+	return list, nil
+}
+
+// Contains 判断地址是否属于列表中的某个网段
+func (l PrefixList) Contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range l {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolver 根据受信任的代理网段解析请求的真实客户端IP
+//
+// 只有直连对端属于受信任代理时才会读取转发头，且只读取配置的一种转发头，
+// 另一种即使存在也被忽略，避免客户端通过未使用的转发头伪造地址。转发链从右向左
+// 遍历，跳过受信任的代理地址，第一个不受信任的地址即为客户端；全部受信任时取最左侧的地址。
+// 没有转发链时使用受信任代理设置的X-Real-IP。未配置受信任代理时始终使用直连对端地址。
+type Resolver struct {
+	trusted PrefixList
+	header  string
+}
+
+// NewResolver 创建客户端IP解析器，trusted为受信任代理的CIDR或单个IP地址，
+// header为受信任代理使用的转发头(xff或forwarded)，为空时使用xff
+func NewResolver(trusted []string, header string) (*Resolver, error) {
+	switch strings.ToLower(header) {
+	case "", HeaderXFF:
+		header = HeaderXFF
+	case HeaderForwarded:
+		header = HeaderForwarded
+	default:
+		return nil, fmt.Errorf("不支持的转发头: %s (可选: xff, forwarded)", header)
+	}
+
+	list, err := ParsePrefixes(trusted)
+	if err != nil {
+		return nil, fmt.Errorf("无效的受信任代理: %v", err)
+	}
+	return &Resolver{trusted: list, header: header}, nil
+}
+
+// Header 返回受信任代理使用的转发头(xff或forwarded)，零值解析器返回xff
+func (r *Resolver) Header() string {
+	if r.header == "" {
+		return HeaderXFF
+	}
+	return r.header
+}
+
+// IsTrusted 判断地址是否属于受信任的代理
+func (r *Resolver) IsTrusted(ip string) bool {
+	return r.trusted.Contains(ip)
+}
+
+// Resolve 返回请求的真实客户端IP
+func (r *Resolver) Resolve(req *http.Request) string {
+	peer := RemoteIP(req)
+	if !r.IsTrusted(peer) {
+		return peer
+	}
+
+	var chain []string
+	if r.Header() == HeaderForwarded {
+		chain = forwardedFor(req.Header.Values("Forwarded"))
+	} else {
+		chain = xForwardedFor(req.Header.Values("X-Forwarded-For"))
+	}
+	if len(chain) == 0 {
+		if ip := parseIP(req.Header.Get("X-Real-IP")); ip != "" {
+			return ip
+		}
+		return peer
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := parseIP(chain[i])
+		if ip == "" {
+			// 无法解析的条目之前的内容不可信，以其右侧最近的地址为准
+			if i == len(chain)-1 {
+				return peer
+			}
+			return parseIP(chain[i+1])
+		}
+		if !r.IsTrusted(ip) {
+			return ip
+		}
+	}
+	return parseIP(chain[0])
+}
+
+// WithClientIP 将客户端IP记录到上下文
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ip)
+}
+
+// FromRequest 返回请求上下文中记录的客户端IP，未记录时返回直连对端地址
+func FromRequest(req *http.Request) string {
+	if ip, ok := req.Context().Value(contextKey{}).(string); ok {
+		return ip
+	}
+	return RemoteIP(req)
+}
+
+// RemoteIP 返回直连对端的IP地址(不含端口)
+func RemoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	if ip := parseIP(host); ip != "" {
+		return ip
+	}
+	return host
+}
+
+// xForwardedFor 拆分X-Forwarded-For头，多个头按出现顺序拼接
+func xForwardedFor(values []string) []string {
+	var chain []string
+	for _, v := range values {
+		for _, entry := range strings.Split(v, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				chain = append(chain, entry)
+			}
+		}
+	}
+	return chain
+}
+
+// forwardedFor 提取RFC 7239 Forwarded头中各节点的for参数
+func forwardedFor(values []string) []string {
+	var chain []string
+	for _, v := range values {
+		for _, element := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					chain = append(chain, strings.Trim(val, `"`))
+				}
+			}
+		}
+	}
+	return chain
+}
+
+// parseIP 解析地址，支持带端口和IPv6方括号的形式，无法解析时返回空字符串
+func parseIP(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr.Unmap().WithZone("").String()
+	}
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return addrPort.Addr().Unmap().WithZone("").String()
+	}
+	// Forwarded中的IPv6地址为方括号形式，可能不带端口
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		return parseIP(s[1 : len(s)-1])
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package clientip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestRequest 创建来自指定对端地址、带有指定请求头的请求
+func newTestRequest(remoteAddr string, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	return req
+}
+
+func TestResolve(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"}
+	tests := []struct {
+		name       string
+		header     string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"不受信任的对端伪造X-Forwarded-For", HeaderXFF, "203.0.113.7:5000",
+			map[string]string{"X-Forwarded-For": "1.2.3.4"}, "203.0.113.7"},
+		{"不受信任的对端伪造X-Real-IP", HeaderXFF, "203.0.113.7:5000",
+			map[string]string{"X-Real-IP": "1.2.3.4"}, "203.0.113.7"},
+		{"不受信任的对端伪造Forwarded", HeaderForwarded, "203.0.113.7:5000",
+			map[string]string{"Forwarded": "for=1.2.3.4"}, "203.0.113.7"},
+		{"受信任代理转发", HeaderXFF, "10.0.0.1:5000",
+			map[string]string{"X-Forwarded-For": "1.2.3.4"}, "1.2.3.4"},
+		{"多级代理从右向左跳过受信任地址", HeaderXFF, "10.0.0.1:5000",
+			map[string]string{"X-Forwarded-For": "1.2.3.4, 192.168.1.1, 10.0.0.2"}, "1.2.3.4"},
+		{"客户端在链首伪造的地址被忽略", HeaderXFF, "10.0.0.1:5000",
+			map[string]string{"X-Forwarded-For": "6.6.6.6, 1.2.3.4, 10.0.0.2"}, "1.2.3.4"},
+		{"链中全部受信任时取最左侧地址", HeaderXFF, "10.0.0.1:5000",
+			map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"最右侧无法解析时使用对端地址", HeaderXFF, "10.0.0.1:5000",
+			map[string]string{"X-Forwarded-For": "1.2.3.4, unknown"}, "10.0.0.1"},
+		{"无法解析的条目之前的地址不可信", HeaderXFF, "10.0.0.1:5000",
+			map[string]string{"X-Forwarded-For": "1.2.3.4, unknown, 10.0.0.2"}, "10.0.0.2"},
+		{"X-Forwarded-For中的IPv6地址", HeaderXFF, "10.0.0.1:5000",
+			map[string]string{"X-Forwarded-For": "2001:db8::1"}, "2001:db8::1"},
+		{"Forwarded中带端口的IPv6地址", HeaderForwarded, "10.0.0.1:5000",
+			map[string]string{"Forwarded": `for="[2001:db8::1]:4711";proto=https`}, "2001:db8::1"},
+		{"Forwarded中不带端口的IPv6地址", HeaderForwarded, "10.0.0.1:5000",
+			map[string]string{"Forwarded": `for=192.0.2.60, for="[2001:db8::2]"`}, "2001:db8::2"},
+		{"Forwarded多级代理", HeaderForwarded, "[fd00::1]:5000",
+			map[string]string{"Forwarded": `for="[2001:db8::3]", for="[fd00::2]";by=lb`}, "2001:db8::3"},
+		{"使用Forwarded时忽略X-Forwarded-For", HeaderForwarded, "10.0.0.1:5000",
+			map[string]string{"X-Forwarded-For": "1.2.3.4"}, "10.0.0.1"},
+		{"使用X-Forwarded-For时忽略Forwarded", HeaderXFF, "10.0.0.1:5000",
+			map[string]string{"Forwarded": "for=1.2.3.4"}, "10.0.0.1"},
+		{"存在转发链时忽略X-Real-IP", HeaderXFF, "10.0.0.1:5000",
+			map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Real-IP": "5.6.7.8"}, "1.2.3.4"},
+		{"存在Forwarded时忽略X-Real-IP", HeaderForwarded, "10.0.0.1:5000",
+			map[string]string{"Forwarded": "for=1.2.3.4", "X-Real-IP": "5.6.7.8"}, "1.2.3.4"},
+		{"没有转发链时使用X-Real-IP", HeaderXFF, "10.0.0.1:5000",
+			map[string]string{"X-Real-IP": "5.6.7.8"}, "5.6.7.8"},
+		{"IPv4映射的对端地址", HeaderXFF, "[::ffff:10.0.0.1]:5000",
+			map[string]string{"X-Forwarded-For": "1.2.3.4"}, "1.2.3.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewResolver(trusted, tt.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Resolve(newTestRequest(tt.remoteAddr, tt.headers)); got != tt.want {
+				t.Errorf("解析结果为 %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveWithoutTrustedProxies(t *testing.T) {
+	req := newTestRequest("10.0.0.1:5000", map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Real-IP": "5.6.7.8"})
+	if got := (&Resolver{}).Resolve(req); got != "10.0.0.1" {
+		t.Errorf("未配置受信任代理时解析结果为 %s，期望对端地址", got)
+	}
+}
+
+func TestNewResolverValidation(t *testing.T) {
+	if _, err := NewResolver(nil, "x-real-ip"); err == nil {
+		t.Error("不支持的转发头未返回错误")
+	}
+	if _, err := NewResolver([]string{"10.0.0.0/33"}, ""); err == nil {
+		t.Error("无效的网段未返回错误")
+	}
+	if _, err := NewResolver([]string{"not-an-ip"}, ""); err == nil {
+		t.Error("无效的IP地址未返回错误")
+	}
+	r, err := NewResolver(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Header() != HeaderXFF {
+		t.Errorf("默认转发头为 %s，期望 %s", r.Header(), HeaderXFF)
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	req := newTestRequest("10.0.0.1:5000", nil)
+	if got := FromRequest(req); got != "10.0.0.1" {
+		t.Errorf("上下文中没有客户端IP时返回 %s，期望对端地址", got)
+	}
+
+	req = req.WithContext(WithClientIP(req.Context(), "1.2.3.4"))
+	if got := FromRequest(req); got != "1.2.3.4" {
+		t.Errorf("返回 %s，期望上下文中记录的 1.2.3.4", got)
+	}
+}
@@ -1,24 +1,250 @@
 package config
 
+import (
+	"encoding/hex"
+	"strings"
+)
+
 // ServerConfig 定义后端服务器配置
 type ServerConfig struct {
 	URL             string `yaml:"url" json:"url" mapstructure:"url"`
 	Weight          int    `yaml:"weight" json:"weight" mapstructure:"weight"`
 	Health          string `yaml:"health" json:"health" mapstructure:"health"`
 	HealthCheckPath string `yaml:"health_check_path" json:"health_check_path" mapstructure:"health_check_path"`
+	Priority        int    `yaml:"priority" json:"priority" mapstructure:"priority"` // 优先级，数值越小越优先
+	Backup          bool   `yaml:"backup" json:"backup" mapstructure:"backup"`       // 备用服务器，排在所有非备用层级之后
+	Zone            string `yaml:"zone" json:"zone" mapstructure:"zone"`             // 所在可用区/地域
+	Rise            int    `yaml:"rise" json:"rise" mapstructure:"rise"`             // 覆盖全局的rise阈值，0表示沿用全局配置
+	Fall            int    `yaml:"fall" json:"fall" mapstructure:"fall"`             // 覆盖全局的fall阈值，0表示沿用全局配置
+	// 该后端的健康检查定义，为空时按health_check_path执行HTTP或TCP检查
+	HealthCheck *ServerHealthCheckConfig `yaml:"health_check" json:"health_check,omitempty" mapstructure:"health_check"`
+}
+
+// ServerHealthCheckConfig 定义单个后端的健康检查方式
+type ServerHealthCheckConfig struct {
+	Type           string               `yaml:"type" json:"type" mapstructure:"type"`       // 检查类型: http(默认)、grpc、tcp、exec
+	Port           int                  `yaml:"port" json:"port" mapstructure:"port"`       // 检查端口，为0时使用流量端口
+	Method         string               `yaml:"method" json:"method" mapstructure:"method"` // 请求方法，默认GET
+	Path           string               `yaml:"path" json:"path" mapstructure:"path"`       // 请求路径，默认health_check_path或全局path
+	Scheme         string               `yaml:"scheme" json:"scheme" mapstructure:"scheme"` // http或https，默认与后端URL一致
+	Host           string               `yaml:"host" json:"host" mapstructure:"host"`       // 覆盖Host头
+	Headers        map[string]string    `yaml:"headers" json:"headers" mapstructure:"headers"`
+	Body           string               `yaml:"body" json:"body" mapstructure:"body"`
+	ExpectedStatus []string             `yaml:"expected_status" json:"expected_status" mapstructure:"expected_status"` // 如["204", "200-399"]，默认200
+	BodyContains   string               `yaml:"body_contains" json:"body_contains" mapstructure:"body_contains"`
+	BodyRegex      string               `yaml:"body_regex" json:"body_regex" mapstructure:"body_regex"`
+	JSONPath       string               `yaml:"json_path" json:"json_path" mapstructure:"json_path"`    // 如"status"、"checks.0.state"
+	JSONValue      string               `yaml:"json_value" json:"json_value" mapstructure:"json_value"` // 为空时要求字段存在且不为null或false
+	Service        string               `yaml:"service" json:"service" mapstructure:"service"`          // grpc检查的服务名，为空表示整个服务器
+	TLS            HealthCheckTLSConfig `yaml:"tls" json:"tls" mapstructure:"tls"`
+	// tcp检查：连接后发送send并要求回复以expect为前缀、匹配expect_regex，二进制数据使用send_hex/expect_hex
+	Send        string `yaml:"send" json:"send" mapstructure:"send"`
+	SendHex     string `yaml:"send_hex" json:"send_hex" mapstructure:"send_hex"`
+	Expect      string `yaml:"expect" json:"expect" mapstructure:"expect"`
+	ExpectHex   string `yaml:"expect_hex" json:"expect_hex" mapstructure:"expect_hex"`
+	ExpectRegex string `yaml:"expect_regex" json:"expect_regex" mapstructure:"expect_regex"`
+	// exec检查：执行命令，退出码为0视为健康，环境变量中包含BACKEND_URL、BACKEND_HOST和BACKEND_PORT
+	Command []string `yaml:"command" json:"command" mapstructure:"command"`
+}
+
+// SendBytes 返回tcp检查要发送的数据
+func (h *ServerHealthCheckConfig) SendBytes() ([]byte, error) {
+	return payload(h.Send, h.SendHex)
+}
+
+// ExpectBytes 返回tcp检查期望的回复前缀
+func (h *ServerHealthCheckConfig) ExpectBytes() ([]byte, error) {
+	return payload(h.Expect, h.ExpectHex)
+}
+
+// payload 返回文本或十六进制形式配置的数据，十六进制中的空白会被忽略
+func payload(text, hexText string) ([]byte, error) {
+	if hexText == "" {
+		return []byte(text), nil
+	}
+	return hex.DecodeString(strings.Join(strings.Fields(hexText), ""))
+}
+
+// HealthCheckTLSConfig 定义健康检查的TLS配置
+type HealthCheckTLSConfig struct {
+	Enabled            bool   `yaml:"enabled" json:"enabled" mapstructure:"enabled"` // 使用TLS，未启用时按scheme或后端URL决定
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" json:"insecure_skip_verify" mapstructure:"insecure_skip_verify"`
+	ServerName         string `yaml:"server_name" json:"server_name" mapstructure:"server_name"`
+	CAFile             string `yaml:"ca_file" json:"ca_file" mapstructure:"ca_file"`
+	CertFile           string `yaml:"cert_file" json:"cert_file" mapstructure:"cert_file"` // 客户端证书，用于双向TLS
+	KeyFile            string `yaml:"key_file" json:"key_file" mapstructure:"key_file"`
+}
+
+// HealthCheckConfig 定义主动健康检查配置
+type HealthCheckConfig struct {
+	Interval      string `yaml:"interval" mapstructure:"interval"`
+	Timeout       string `yaml:"timeout" mapstructure:"timeout"`
+	Path          string `yaml:"path" mapstructure:"path"`
+	RetryCount    int    `yaml:"retry_count" mapstructure:"retry_count"`       // 单次检查内的重试次数
+	RetryInterval string `yaml:"retry_interval" mapstructure:"retry_interval"` // 单次检查内的重试间隔
+	MaxFailures   int    `yaml:"max_failures" mapstructure:"max_failures"`     // 重试池中连续失败该次数后进入隔离
+	Rise          int    `yaml:"rise" mapstructure:"rise"`                     // 连续成功该次数后标记为可用，默认1
+	Fall          int    `yaml:"fall" mapstructure:"fall"`                     // 连续失败该次数后标记为不可用，默认1
+	// 隔离中的后端探测失败后按指数退避重新探测，默认10s起、最长5m
+	QuarantineBackoff    string `yaml:"quarantine_backoff" mapstructure:"quarantine_backoff"`
+	QuarantineMaxBackoff string `yaml:"quarantine_max_backoff" mapstructure:"quarantine_max_backoff"`
+}
+
+// StickyConfig 定义基于Cookie的会话保持配置
+type StickyConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// 模式: lb(负载均衡器签发Cookie) 或 app(跟随应用Cookie，如JSESSIONID)
+	Mode string `yaml:"mode" mapstructure:"mode"`
+	// Cookie名称，app模式下为应用会话Cookie名称
+	CookieName string `yaml:"cookie_name" mapstructure:"cookie_name"`
+	// 会话有效期，如 1h，为空表示浏览器会话期间有效；app模式下为空闲过期时间，为空时默认30m
+	TTL string `yaml:"ttl" mapstructure:"ttl"`
+	// SameSite属性: lax, strict, none
+	SameSite string `yaml:"same_site" mapstructure:"same_site"`
+	Secure   bool   `yaml:"secure" mapstructure:"secure"`
+	HTTPOnly bool   `yaml:"http_only" mapstructure:"http_only"`
+	// Cookie签名密钥，为空时每次启动随机生成
+	Secret string `yaml:"secret" mapstructure:"secret"`
+	// app模式下最多记录的会话数，超出时淘汰最久未使用的会话，为0时默认100000
+	MaxSessions int `yaml:"max_sessions" mapstructure:"max_sessions"`
+}
+
+// SlowStartConfig 定义后端恢复后的慢启动配置
+type SlowStartConfig struct {
+	// 慢启动时长，如 30s，为空表示不启用
+	Duration string `yaml:"duration" mapstructure:"duration"`
+	// 权重增长方式: linear 或 exponential
+	Mode string `yaml:"mode" mapstructure:"mode"`
+	// 起始有效权重占配置权重的比例，取值(0,1]，默认0.1
+	MinRatio float64 `yaml:"min_ratio" mapstructure:"min_ratio"`
+}
+
+// LoadFeedbackConfig 定义根据后端上报负载动态调整权重的配置
+type LoadFeedbackConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// 后端上报负载的响应头，默认X-Backend-Load，该响应头不会返回给客户端
+	Header string `yaml:"header" mapstructure:"header"`
+	// 响应头格式: kv(如 cpu=0.83)、orca(endpoint-load-metrics) 或 number
+	Format string `yaml:"format" mapstructure:"format"`
+	// 使用的指标名称，kv格式默认cpu，orca格式默认cpu_utilization
+	Metric string `yaml:"metric" mapstructure:"metric"`
+	// 平滑窗口，如 10s
+	Window string `yaml:"window" mapstructure:"window"`
+	// 有效权重系数的下限和上限，默认0.1和1
+	MinRatio float64 `yaml:"min_ratio" mapstructure:"min_ratio"`
+	MaxRatio float64 `yaml:"max_ratio" mapstructure:"max_ratio"`
+}
+
+// SubsetConfig 定义确定性子集划分配置
+type SubsetConfig struct {
+	// 本实例编号，取值[0, instance_count)
+	InstanceID int `yaml:"instance_id" mapstructure:"instance_id"`
+	// 负载均衡器实例总数，0表示不启用子集划分
+	InstanceCount int `yaml:"instance_count" mapstructure:"instance_count"`
+	// 每个实例使用的后端数，默认10，且不小于 ceil(后端数/实例数)
+	Size int `yaml:"size" mapstructure:"size"`
+	// 划分方式: aperture(默认) 或 rendezvous
+	Method string `yaml:"method" mapstructure:"method"`
+}
+
+// OutlierDetectionConfig 定义被动健康检查(离群检测)配置，数值为0时使用默认值
+type OutlierDetectionConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// 连续5xx响应次数阈值，默认5
+	Consecutive5xx int `yaml:"consecutive_5xx" mapstructure:"consecutive_5xx"`
+	// 连续网关错误(502/503/504、连接失败)次数阈值，默认5
+	ConsecutiveGatewayErrors int `yaml:"consecutive_gateway_errors" mapstructure:"consecutive_gateway_errors"`
+	// 成功率统计和驱逐到期检查的周期，默认10s
+	Interval string `yaml:"interval" mapstructure:"interval"`
+	// 基础驱逐时长，实际时长为 基础时长×驱逐次数，默认30s
+	BaseEjectionTime string `yaml:"base_ejection_time" mapstructure:"base_ejection_time"`
+	// 驱逐时长上限，默认300s
+	MaxEjectionTime string `yaml:"max_ejection_time" mapstructure:"max_ejection_time"`
+	// 同时被驱逐的后端占比上限，默认10
+	MaxEjectionPercent float64 `yaml:"max_ejection_percent" mapstructure:"max_ejection_percent"`
+	// 参与成功率检测的最少后端数，默认5
+	SuccessRateMinHosts int `yaml:"success_rate_min_hosts" mapstructure:"success_rate_min_hosts"`
+	// 后端在一个周期内参与成功率检测的最少请求数，默认100
+	SuccessRateRequestVolume int `yaml:"success_rate_request_volume" mapstructure:"success_rate_request_volume"`
+	// 成功率低于 均值-系数×标准差 时驱逐，默认1.9
+	SuccessRateStdevFactor float64 `yaml:"success_rate_stdev_factor" mapstructure:"success_rate_stdev_factor"`
+}
+
+// CircuitBreakerConfig 定义后端熔断器配置，数值为0时使用默认值
+type CircuitBreakerConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// 滚动统计窗口，默认10s
+	Window string `yaml:"window" mapstructure:"window"`
+	// 窗口内请求数达到该值才会判断是否熔断，默认20
+	MinRequests int `yaml:"min_requests" mapstructure:"min_requests"`
+	// 失败(5xx、转发失败)百分比阈值，默认50
+	ErrorThreshold float64 `yaml:"error_threshold" mapstructure:"error_threshold"`
+	// 超时(转发超时、慢响应)百分比阈值，默认50
+	TimeoutThreshold float64 `yaml:"timeout_threshold" mapstructure:"timeout_threshold"`
+	// 响应时间超过该值视为超时，为空表示只统计转发超时
+	SlowCallDuration string `yaml:"slow_call_duration" mapstructure:"slow_call_duration"`
+	// 熔断持续时间，之后进入半开状态，默认30s
+	OpenDuration string `yaml:"open_duration" mapstructure:"open_duration"`
+	// 半开状态下允许的探测请求数，全部成功后恢复，默认3
+	HalfOpenProbes int `yaml:"half_open_probes" mapstructure:"half_open_probes"`
+}
+
+// FailoverConfig 定义优先级层级的故障切换配置
+type FailoverConfig struct {
+	// 层级健康容量(存活后端权重占比)低于该百分比时切换到下一层级，0表示仅在层级内没有存活后端时切换
+	MinHealthyPercent float64 `yaml:"min_healthy_percent" mapstructure:"min_healthy_percent"`
+}
+
+// ZoneRoutingConfig 定义可用区感知路由配置
+type ZoneRoutingConfig struct {
+	// 本地可用区健康容量不低于该百分比时全部流量留在本地，未配置时默认70，必须大于0
+	MinHealthyPercent float64 `yaml:"min_healthy_percent" mapstructure:"min_healthy_percent"`
+	// 本地单位容量的进行中请求数超过全局平均值的该倍数时视为过载，默认1.5
+	OverloadFactor float64 `yaml:"overload_factor" mapstructure:"overload_factor"`
+}
+
+// RateLimitConfig 定义按客户端IP的限流配置
+type RateLimitConfig struct {
+	// 每个客户端在每个时间窗口内允许的请求数，0表示不限流
+	Requests int `yaml:"requests" mapstructure:"requests"`
+	// 时间窗口，默认1s
+	Interval string `yaml:"interval" mapstructure:"interval"`
+}
+
+// ACLConfig 定义按客户端IP的访问控制配置
+type ACLConfig struct {
+	// 允许访问的CIDR或IP，为空表示允许所有地址
+	Allow []string `yaml:"allow" mapstructure:"allow"`
+	// 拒绝访问的CIDR或IP，优先于allow
+	Deny []string `yaml:"deny" mapstructure:"deny"`
 }
 
 // LBConfig 负载均衡器配置
 type LBConfig struct {
-	ListenAddr  string         `yaml:"listen_addr" mapstructure:"listen_addr"`
-	Algorithm   string         `yaml:"algorithm" mapstructure:"algorithm"`
-	Servers     []ServerConfig `yaml:"servers" mapstructure:"servers"`
-	HealthCheck struct {
-		Interval      string `yaml:"interval" mapstructure:"interval"`
-		Timeout       string `yaml:"timeout" mapstructure:"timeout"`
-		Path          string `yaml:"path" mapstructure:"path"`
-		RetryCount    int    `yaml:"retry_count" mapstructure:"retry_count"`
-		RetryInterval string `yaml:"retry_interval" mapstructure:"retry_interval"`
-		MaxFailures   int    `yaml:"max_failures" mapstructure:"max_failures"`
-	} `yaml:"health_check" mapstructure:"health_check"`
+	ListenAddr string `yaml:"listen_addr" mapstructure:"listen_addr"`
+	// 管理接口的独立监听地址，如127.0.0.1:9090，为空表示不提供管理接口
+	AdminAddr string `yaml:"admin_addr" mapstructure:"admin_addr"`
+	// 管理接口令牌，非空时请求需携带 Authorization: Bearer <token>；监听非本地回环地址时必须配置
+	AdminToken string `yaml:"admin_token" mapstructure:"admin_token"`
+	Algorithm  string `yaml:"algorithm" mapstructure:"algorithm"`
+	// 算法参数，由所选算法解码为自己的参数结构体
+	AlgorithmOptions map[string]interface{} `yaml:"algorithm_options" mapstructure:"algorithm_options"`
+	Sticky           StickyConfig           `yaml:"sticky" mapstructure:"sticky"`
+	SlowStart        SlowStartConfig        `yaml:"slow_start" mapstructure:"slow_start"`
+	LoadFeedback     LoadFeedbackConfig     `yaml:"load_feedback" mapstructure:"load_feedback"`
+	Subset           SubsetConfig           `yaml:"subset" mapstructure:"subset"`
+	Failover         FailoverConfig         `yaml:"failover" mapstructure:"failover"`
+	OutlierDetection OutlierDetectionConfig `yaml:"outlier_detection" mapstructure:"outlier_detection"`
+	CircuitBreaker   CircuitBreakerConfig   `yaml:"circuit_breaker" mapstructure:"circuit_breaker"`
+	Zone             string                 `yaml:"zone" mapstructure:"zone"` // 本实例所在可用区，为空表示不启用可用区感知路由
+	ZoneRouting      ZoneRoutingConfig      `yaml:"zone_routing" mapstructure:"zone_routing"`
+	Servers          []ServerConfig         `yaml:"servers" mapstructure:"servers"`
+	TrustedProxies   []string               `yaml:"trusted_proxies" mapstructure:"trusted_proxies"` // 受信任代理的CIDR或IP，只信任来自这些地址的转发头
+	HealthCheck      HealthCheckConfig      `yaml:"health_check" mapstructure:"health_check"`
+	// 受信任代理使用的转发头: xff(默认) 或 forwarded，另一种转发头被忽略
+	ForwardedHeader string `yaml:"forwarded_header" mapstructure:"forwarded_header"`
+	// 是否记录每个请求的访问日志
+	AccessLog bool            `yaml:"access_log" mapstructure:"access_log"`
+	RateLimit RateLimitConfig `yaml:"rate_limit" mapstructure:"rate_limit"`
+	ACL       ACLConfig       `yaml:"acl" mapstructure:"acl"`
 }
@@ -2,11 +2,18 @@ package config
 
 import (
 	"fmt"
+	"go-load-balancer/internal/algorithms"
 	"path/filepath"
 
 	"github.com/spf13/viper"
 )
 
+// sensitiveKeys 打印配置时需要隐藏取值的配置项
+var sensitiveKeys = map[string]bool{
+	"admin_token":   true,
+	"sticky.secret": true,
+}
+
 // LoadConfig 从指定路径加载配置
 func LoadConfig(configPath string) (*LBConfig, error) {
 	// 设置viper配置
@@ -37,10 +44,19 @@ func LoadConfig(configPath string) (*LBConfig, error) {
 		return nil, fmt.Errorf("读取配置文件失败: %v", err)
 	}
 
+	// 启用可用区感知路由时，未配置的最小健康容量百分比使用默认值，使校验可以拒绝显式配置的0
+	if v.GetString("zone") != "" {
+		v.SetDefault("zone_routing.min_healthy_percent", algorithms.DefaultZoneMinHealthyPercent)
+	}
+
 	// 打印原始配置
 	fmt.Println("Raw config values:")
 	for _, key := range v.AllKeys() {
-		fmt.Printf("%s: %v\n", key, v.Get(key))
+		value := v.Get(key)
+		if sensitiveKeys[key] {
+			value = "******"
+		}
+		fmt.Printf("%s: %v\n", key, value)
 	}
 
 	// 解析配置到结构体
@@ -49,8 +65,5 @@ func LoadConfig(configPath string) (*LBConfig, error) {
 		return nil, fmt.Errorf("解析配置失败: %v", err)
 	}
 
-	// 调试日志
-	fmt.Printf("Loaded config: %+v\n", cfg)
-
 	return &cfg, nil
 }
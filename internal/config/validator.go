@@ -2,8 +2,14 @@ package config
 
 import (
 	"fmt"
+	"go-load-balancer/internal/algorithms"
+	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/clientip"
+	"net"
 	"net/url"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // Validate 验证配置是否有效
@@ -13,15 +19,29 @@ func (c *LBConfig) Validate() error {
 		return fmt.Errorf("监听地址不能为空")
 	}
 
-	// 验证算法类型
-	supportedAlgorithms := map[string]bool{
-		"round_robin": true,
-		"least_conn":  true,
-		"weighted_rr": true,
-		"ip_hash":     true,
+	// 验证管理接口配置
+	if err := validateAdmin(c.AdminAddr, c.AdminToken); err != nil {
+		return err
 	}
-	if !supportedAlgorithms[strings.ToLower(c.Algorithm)] {
-		return fmt.Errorf("不支持的负载均衡算法: %s", c.Algorithm)
+
+	// 验证算法类型及算法参数(由算法注册表校验)
+	if err := algorithms.ValidateOptions(c.Algorithm, c.AlgorithmOptions); err != nil {
+		return err
+	}
+
+	// 验证会话保持配置
+	if err := c.Sticky.validate(); err != nil {
+		return err
+	}
+
+	// 验证慢启动配置
+	if err := c.SlowStart.validate(); err != nil {
+		return err
+	}
+
+	// 验证负载反馈配置
+	if err := c.LoadFeedback.validate(); err != nil {
+		return err
 	}
 
 	// 验证后端服务器
@@ -33,7 +53,350 @@ func (c *LBConfig) Validate() error {
 		if _, err := url.ParseRequestURI(server.URL); err != nil {
 			return fmt.Errorf("无效的后端服务器URL: %s", server.URL)
 		}
+		if server.Priority < 0 {
+			return fmt.Errorf("后端服务器 %s 的优先级不能为负数", server.URL)
+		}
+		if server.Rise < 0 || server.Fall < 0 {
+			return fmt.Errorf("后端服务器 %s 的rise/fall阈值不能为负数", server.URL)
+		}
+		if server.HealthCheck != nil {
+			if err := server.HealthCheck.validate(); err != nil {
+				return fmt.Errorf("后端服务器 %s 的健康检查配置无效: %v", server.URL, err)
+			}
+		}
+	}
+
+	// 验证健康检查配置
+	if err := c.HealthCheck.validate(); err != nil {
+		return err
+	}
+
+	// 验证受信任代理配置
+	if _, err := clientip.NewResolver(c.TrustedProxies, c.ForwardedHeader); err != nil {
+		return err
+	}
+
+	// 验证限流和访问控制配置
+	if c.RateLimit.Requests < 0 {
+		return fmt.Errorf("限流请求数不能为负数: %d", c.RateLimit.Requests)
+	}
+	if c.RateLimit.Interval != "" {
+		if d, err := time.ParseDuration(c.RateLimit.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("无效的限流时间窗口: %s", c.RateLimit.Interval)
+		}
+	}
+	if _, err := clientip.ParsePrefixes(c.ACL.Allow); err != nil {
+		return fmt.Errorf("无效的访问控制allow列表: %v", err)
+	}
+	if _, err := clientip.ParsePrefixes(c.ACL.Deny); err != nil {
+		return fmt.Errorf("无效的访问控制deny列表: %v", err)
+	}
+
+	// 验证可用区感知路由配置，只有配置了本实例所在可用区时才启用
+	if c.Zone != "" {
+		if p := c.ZoneRouting.MinHealthyPercent; p <= 0 || p > 100 {
+			return fmt.Errorf("可用区最小健康容量百分比必须大于0且不大于100: %v", p)
+		}
+		if f := c.ZoneRouting.OverloadFactor; f != 0 && f < 1 {
+			return fmt.Errorf("可用区过载系数必须不小于1: %v", f)
+		}
+	}
+
+	// 验证子集划分配置
+	if err := c.Subset.validate(); err != nil {
+		return err
+	}
+
+	// 验证故障切换配置
+	if p := c.Failover.MinHealthyPercent; p < 0 || p > 100 {
+		return fmt.Errorf("最小健康容量百分比必须在0-100之间: %v", p)
+	}
+
+	// 验证离群检测配置
+	if err := c.OutlierDetection.validate(); err != nil {
+		return err
+	}
+
+	// 验证熔断器配置
+	if err := c.CircuitBreaker.validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validate 验证健康检查配置
+func (h *HealthCheckConfig) validate() error {
+	durations := []struct {
+		name  string
+		value string
+	}{
+		{"健康检查间隔", h.Interval},
+		{"健康检查超时", h.Timeout},
+		{"健康检查重试间隔", h.RetryInterval},
+		{"隔离退避时间", h.QuarantineBackoff},
+		{"隔离最长退避时间", h.QuarantineMaxBackoff},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		if v, err := time.ParseDuration(d.value); err != nil || v < 0 {
+			return fmt.Errorf("无效的%s: %s", d.name, d.value)
+		}
+	}
+
+	if h.RetryCount < 0 {
+		return fmt.Errorf("健康检查重试次数不能为负数: %d", h.RetryCount)
+	}
+	if h.MaxFailures < 0 {
+		return fmt.Errorf("健康检查最大失败次数不能为负数: %d", h.MaxFailures)
+	}
+	if h.Rise < 0 || h.Fall < 0 {
+		return fmt.Errorf("健康检查rise/fall阈值不能为负数: rise=%d, fall=%d", h.Rise, h.Fall)
+	}
+	return nil
+}
+
+// validate 验证单个后端的健康检查配置
+func (h *ServerHealthCheckConfig) validate() error {
+	switch strings.ToLower(h.Type) {
+	case "", "http", "grpc", "tcp":
+	case "exec":
+		if len(h.Command) == 0 || h.Command[0] == "" {
+			return fmt.Errorf("exec健康检查需要指定command")
+		}
+	default:
+		return fmt.Errorf("不支持的健康检查类型: %s", h.Type)
 	}
 
+	if h.Port < 0 || h.Port > 65535 {
+		return fmt.Errorf("无效的检查端口: %d", h.Port)
+	}
+	switch strings.ToLower(h.Scheme) {
+	case "", "http", "https":
+	default:
+		return fmt.Errorf("不支持的健康检查协议: %s", h.Scheme)
+	}
+	if _, err := backend.ParseStatusRanges(h.ExpectedStatus); err != nil {
+		return err
+	}
+	if h.BodyRegex != "" {
+		if _, err := regexp.Compile(h.BodyRegex); err != nil {
+			return fmt.Errorf("无效的响应体正则表达式: %v", err)
+		}
+	}
+	if h.ExpectRegex != "" {
+		if _, err := regexp.Compile(h.ExpectRegex); err != nil {
+			return fmt.Errorf("无效的回复正则表达式: %v", err)
+		}
+	}
+	if h.Send != "" && h.SendHex != "" {
+		return fmt.Errorf("send和send_hex不能同时配置")
+	}
+	if h.Expect != "" && h.ExpectHex != "" {
+		return fmt.Errorf("expect和expect_hex不能同时配置")
+	}
+	if _, err := h.SendBytes(); err != nil {
+		return fmt.Errorf("无效的send_hex: %v", err)
+	}
+	if _, err := h.ExpectBytes(); err != nil {
+		return fmt.Errorf("无效的expect_hex: %v", err)
+	}
+	if (h.TLS.CertFile == "") != (h.TLS.KeyFile == "") {
+		return fmt.Errorf("客户端证书cert_file和key_file必须同时配置")
+	}
+	return nil
+}
+
+// validate 验证会话保持配置
+func (s *StickyConfig) validate() error {
+	if !s.Enabled {
+		return nil
+	}
+
+	switch strings.ToLower(s.Mode) {
+	case "", "lb":
+	case "app":
+		if s.CookieName == "" {
+			return fmt.Errorf("app会话保持模式需要指定cookie_name")
+		}
+	default:
+		return fmt.Errorf("不支持的会话保持模式: %s", s.Mode)
+	}
+
+	switch strings.ToLower(s.SameSite) {
+	case "", "lax", "strict", "none":
+	default:
+		return fmt.Errorf("无效的SameSite属性: %s", s.SameSite)
+	}
+	if strings.EqualFold(s.SameSite, "none") && !s.Secure {
+		return fmt.Errorf("SameSite=None的Cookie必须设置secure")
+	}
+
+	if s.TTL != "" {
+		if ttl, err := time.ParseDuration(s.TTL); err != nil || ttl < 0 {
+			return fmt.Errorf("无效的会话有效期: %s", s.TTL)
+		}
+	}
+	if s.MaxSessions < 0 {
+		return fmt.Errorf("会话数上限不能为负数: %d", s.MaxSessions)
+	}
+	return nil
+}
+
+// validate 验证慢启动配置
+func (s *SlowStartConfig) validate() error {
+	if s.Duration == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(s.Duration); err != nil || d < 0 {
+		return fmt.Errorf("无效的慢启动时长: %s", s.Duration)
+	}
+
+	switch strings.ToLower(s.Mode) {
+	case "", "linear", "exponential":
+	default:
+		return fmt.Errorf("不支持的慢启动方式: %s", s.Mode)
+	}
+
+	if s.MinRatio < 0 || s.MinRatio > 1 {
+		return fmt.Errorf("慢启动起始比例必须在(0,1]之间: %v", s.MinRatio)
+	}
+	return nil
+}
+
+// validate 验证负载反馈配置
+func (l *LoadFeedbackConfig) validate() error {
+	if !l.Enabled {
+		return nil
+	}
+
+	switch strings.ToLower(l.Format) {
+	case "", "kv", "orca", "number":
+	default:
+		return fmt.Errorf("不支持的负载上报格式: %s", l.Format)
+	}
+
+	if l.Window != "" {
+		if d, err := time.ParseDuration(l.Window); err != nil || d < 0 {
+			return fmt.Errorf("无效的负载反馈平滑窗口: %s", l.Window)
+		}
+	}
+
+	if l.MinRatio < 0 || l.MinRatio > 1 {
+		return fmt.Errorf("负载反馈系数下限必须在0-1之间: %v", l.MinRatio)
+	}
+	if l.MaxRatio < 0 || l.MaxRatio > 1 {
+		return fmt.Errorf("负载反馈系数上限必须在0-1之间: %v", l.MaxRatio)
+	}
+	if l.MaxRatio != 0 && l.MaxRatio < l.MinRatio {
+		return fmt.Errorf("负载反馈系数上限不能小于下限: %v < %v", l.MaxRatio, l.MinRatio)
+	}
+	return nil
+}
+
+// validate 验证子集划分配置
+func (s *SubsetConfig) validate() error {
+	if s.InstanceCount == 0 {
+		return nil
+	}
+	if s.InstanceCount < 0 {
+		return fmt.Errorf("负载均衡器实例总数不能为负数: %d", s.InstanceCount)
+	}
+	if s.InstanceID < 0 || s.InstanceID >= s.InstanceCount {
+		return fmt.Errorf("实例编号必须在[0, %d)之间: %d", s.InstanceCount, s.InstanceID)
+	}
+	if s.Size < 0 {
+		return fmt.Errorf("子集大小不能为负数: %d", s.Size)
+	}
+
+	switch strings.ToLower(s.Method) {
+	case "", "rendezvous", "aperture":
+	default:
+		return fmt.Errorf("不支持的子集划分方式: %s", s.Method)
+	}
+	return nil
+}
+
+// validate 验证离群检测配置
+func (o *OutlierDetectionConfig) validate() error {
+	if !o.Enabled {
+		return nil
+	}
+
+	durations := []struct{ name, value string }{
+		{"离群检测周期", o.Interval},
+		{"基础驱逐时长", o.BaseEjectionTime},
+		{"驱逐时长上限", o.MaxEjectionTime},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		if v, err := time.ParseDuration(d.value); err != nil || v < 0 {
+			return fmt.Errorf("无效的%s: %s", d.name, d.value)
+		}
+	}
+
+	if o.Consecutive5xx < 0 || o.ConsecutiveGatewayErrors < 0 {
+		return fmt.Errorf("连续错误次数阈值不能为负数")
+	}
+	if p := o.MaxEjectionPercent; p < 0 || p > 100 {
+		return fmt.Errorf("最大驱逐百分比必须在0-100之间: %v", p)
+	}
+	if o.SuccessRateMinHosts < 0 || o.SuccessRateRequestVolume < 0 || o.SuccessRateStdevFactor < 0 {
+		return fmt.Errorf("成功率检测参数不能为负数")
+	}
+	return nil
+}
+
+// validate 验证熔断器配置
+func (cb *CircuitBreakerConfig) validate() error {
+	if !cb.Enabled {
+		return nil
+	}
+
+	durations := []struct{ name, value string }{
+		{"熔断统计窗口", cb.Window},
+		{"慢响应阈值", cb.SlowCallDuration},
+		{"熔断持续时间", cb.OpenDuration},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		if v, err := time.ParseDuration(d.value); err != nil || v < 0 {
+			return fmt.Errorf("无效的%s: %s", d.name, d.value)
+		}
+	}
+
+	if p := cb.ErrorThreshold; p < 0 || p > 100 {
+		return fmt.Errorf("熔断失败率阈值必须在0-100之间: %v", p)
+	}
+	if p := cb.TimeoutThreshold; p < 0 || p > 100 {
+		return fmt.Errorf("熔断超时率阈值必须在0-100之间: %v", p)
+	}
+	if cb.MinRequests < 0 || cb.HalfOpenProbes < 0 {
+		return fmt.Errorf("熔断器请求数参数不能为负数")
+	}
+	return nil
+}
+
+// validateAdmin 验证管理接口配置，监听非本地回环地址时必须配置令牌
+func validateAdmin(addr, token string) error {
+	if addr == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("无效的管理接口监听地址 %s: %v", addr, err)
+	}
+	if token != "" || host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("管理接口监听非本地回环地址 %s 时必须配置admin_token", addr)
+	}
 	return nil
 }
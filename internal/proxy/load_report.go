@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 负载上报头的解析格式
+const (
+	// LoadFormatKV 键值对格式，如 "cpu=0.83, mem=0.41"
+	LoadFormatKV = "kv"
+	// LoadFormatORCA ORCA endpoint-load-metrics 的TEXT或JSON格式，
+	// 如 "TEXT cpu_utilization=0.83, mem_utilization=0.41"
+	LoadFormatORCA = "orca"
+	// LoadFormatNumber 单个数值，如 "0.83"
+	LoadFormatNumber = "number"
+)
+
+// LoadReportOptions 后端负载上报的解析参数
+type LoadReportOptions struct {
+	Header string // 响应头名称，如 X-Backend-Load 或 endpoint-load-metrics
+	Format string // kv, orca 或 number
+	Metric string // 使用的指标名称，kv格式默认cpu，orca格式默认cpu_utilization
+}
+
+// loadReportParser 从响应头中解析后端利用率
+type loadReportParser struct {
+	header string
+	format string
+	metric string
+}
+
+// newLoadReportParser 创建负载上报解析器
+func newLoadReportParser(opts LoadReportOptions) (*loadReportParser, error) {
+	if opts.Header == "" {
+		return nil, fmt.Errorf("负载上报头名称不能为空")
+	}
+
+	p := &loadReportParser{
+		header: opts.Header,
+		format: strings.ToLower(opts.Format),
+		metric: opts.Metric,
+	}
+	switch p.format {
+	case "", LoadFormatKV:
+		p.format = LoadFormatKV
+		if p.metric == "" {
+			p.metric = "cpu"
+		}
+	case LoadFormatORCA:
+		if p.metric == "" {
+			p.metric = "cpu_utilization"
+		}
+	case LoadFormatNumber:
+	default:
+		return nil, fmt.Errorf("不支持的负载上报格式: %s", opts.Format)
+	}
+	return p, nil
+}
+
+// parse 解析响应头的值，返回利用率
+func (p *loadReportParser) parse(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	switch p.format {
+	case LoadFormatNumber:
+		return parseLoadValue(value)
+	case LoadFormatORCA:
+		if rest, ok := strings.CutPrefix(value, "JSON "); ok {
+			return p.parseORCAJSON(rest)
+		}
+		// TEXT格式与键值对格式相同，只是带有前缀
+		value = strings.TrimPrefix(value, "TEXT ")
+	}
+	return p.parseKV(value)
+}
+
+// parseKV 解析逗号分隔的键值对
+func (p *loadReportParser) parseKV(value string) (float64, bool) {
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if ok && strings.TrimSpace(key) == p.metric {
+			return parseLoadValue(strings.TrimSpace(val))
+		}
+	}
+	return 0, false
+}
+
+// parseORCAJSON 解析ORCA的JSON格式，支持顶层字段和named_metrics中的自定义指标
+func (p *loadReportParser) parseORCAJSON(value string) (float64, bool) {
+	var report map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &report); err != nil {
+		return 0, false
+	}
+
+	raw, ok := report[p.metric]
+	if !ok {
+		name, isNamed := strings.CutPrefix(p.metric, "named_metrics.")
+		if !isNamed {
+			return 0, false
+		}
+		var named map[string]float64
+		if err := json.Unmarshal(report["named_metrics"], &named); err != nil {
+			return 0, false
+		}
+		v, ok := named[name]
+		return v, ok
+	}
+
+	var v float64
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// parseLoadValue 解析利用率数值，支持百分号形式如 "83%"
+func parseLoadValue(s string) (float64, bool) {
+	percent := strings.HasSuffix(s, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, false
+	}
+	if percent {
+		v /= 100
+	}
+	return v, true
+}
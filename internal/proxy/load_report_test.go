@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"go-load-balancer/internal/algorithms"
+	"go-load-balancer/internal/backend"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoadReportParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   LoadReportOptions
+		value  string
+		want   float64
+		wantOK bool
+	}{
+		{"键值对默认指标", LoadReportOptions{Header: "X-Backend-Load"}, "cpu=0.83, mem=0.41", 0.83, true},
+		{"键值对指定指标", LoadReportOptions{Header: "X-Backend-Load", Metric: "mem"}, "cpu=0.83, mem=0.41", 0.41, true},
+		{"键值对百分比", LoadReportOptions{Header: "X-Backend-Load"}, "cpu=83%", 0.83, true},
+		{"键值对缺少指标", LoadReportOptions{Header: "X-Backend-Load"}, "mem=0.41", 0, false},
+		{"键值对无效数值", LoadReportOptions{Header: "X-Backend-Load"}, "cpu=high", 0, false},
+		{"单个数值", LoadReportOptions{Header: "X-Backend-Load", Format: LoadFormatNumber}, " 0.5 ", 0.5, true},
+		{"单个百分比", LoadReportOptions{Header: "X-Backend-Load", Format: LoadFormatNumber}, "40%", 0.4, true},
+		{"空值", LoadReportOptions{Header: "X-Backend-Load", Format: LoadFormatNumber}, "  ", 0, false},
+		{"ORCA文本格式", LoadReportOptions{Header: "endpoint-load-metrics", Format: LoadFormatORCA}, "TEXT cpu_utilization=0.83, mem_utilization=0.41", 0.83, true},
+		{"ORCA JSON格式", LoadReportOptions{Header: "endpoint-load-metrics", Format: "ORCA"}, `JSON {"cpu_utilization": 0.62}`, 0.62, true},
+		{"ORCA自定义指标", LoadReportOptions{Header: "endpoint-load-metrics", Format: LoadFormatORCA, Metric: "named_metrics.queue"},
+			`JSON {"cpu_utilization": 0.62, "named_metrics": {"queue": 0.3}}`, 0.3, true},
+		{"ORCA无效JSON", LoadReportOptions{Header: "endpoint-load-metrics", Format: LoadFormatORCA}, `JSON {"cpu_utilization":`, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newLoadReportParser(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := p.parse(tt.value)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("解析 %q 得到 (%v, %v)，期望 (%v, %v)", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLoadReportOptionsValidation(t *testing.T) {
+	if _, err := newLoadReportParser(LoadReportOptions{}); err == nil {
+		t.Error("负载上报头名称为空时未返回错误")
+	}
+	if _, err := newLoadReportParser(LoadReportOptions{Header: "X-Backend-Load", Format: "xml"}); err == nil {
+		t.Error("不支持的格式未返回错误")
+	}
+}
+
+func TestLoadReportHeaderStrippedFromResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Backend-Load", "cpu=0.75")
+		w.Header().Set("X-Other", "kept")
+	}))
+	defer srv.Close()
+
+	b := newTestBackend(t, srv.URL)
+	backends := []*backend.Backend{b}
+	rp := NewReverseProxy(backend.NewPool(backends), algorithms.NewRoundRobin(backends), nil)
+	if err := rp.EnableLoadReports(LoadReportOptions{Header: "X-Backend-Load"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	rp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("响应状态码为 %d", rec.Code)
+	}
+	if v := rec.Header().Get("X-Backend-Load"); v != "" {
+		t.Errorf("负载上报头被返回给了客户端: %q", v)
+	}
+	if v := rec.Header().Get("X-Other"); v != "kept" {
+		t.Errorf("其他响应头被删除: %q", v)
+	}
+	if got := b.ReportedLoad(); got != 0.75 {
+		t.Errorf("后端记录的利用率为 %v，期望 0.75", got)
+	}
+	if got := b.LoadFactor(); math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("后端负载反馈系数为 %v，期望 0.25", got)
+	}
+}
@@ -1,39 +1,73 @@
 package proxy
 
 import (
+	"go-load-balancer/internal/clientip"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 )
 
 // Middleware 中间件函数类型
 type Middleware func(http.Handler) http.Handler
 
+// ClientIPMiddleware 解析真实客户端IP并记录到请求上下文，应放在日志、限流等中间件之前
+func ClientIPMiddleware(resolver *clientip.Resolver) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := clientip.WithClientIP(r.Context(), resolver.Resolve(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // LoggingMiddleware 记录请求日志
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		next.ServeHTTP(w, r)
-		log.Printf("%s %s %v", r.Method, r.URL.Path, time.Since(start))
+		log.Printf("%s %s %s %v", clientip.FromRequest(r), r.Method, r.URL.Path, time.Since(start))
 	})
 }
 
-// RateLimitMiddleware 限流中间件
+// ACLMiddleware 按客户端IP进行访问控制：属于deny的地址被拒绝，allow非空时只允许其中的地址
+func ACLMiddleware(allow, deny clientip.PrefixList) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientip.FromRequest(r)
+			if deny.Contains(ip) || (len(allow) > 0 && !allow.Contains(ip)) {
+				http.Error(w, "禁止访问", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitMiddleware 按客户端IP限流，每个客户端在每个时间窗口内最多limit个请求
 func RateLimitMiddleware(limit int, interval time.Duration) Middleware {
-	ticker := time.NewTicker(interval)
-	counter := 0
+	var mu sync.Mutex
+	counters := make(map[string]int)
+	windowStart := time.Now()
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			select {
-			case <-ticker.C:
-				counter = 0
-			default:
-				if counter >= limit {
-					http.Error(w, "请求过于频繁", http.StatusTooManyRequests)
-					return
-				}
-				counter++
+			ip := clientip.FromRequest(r)
+
+			mu.Lock()
+			if time.Since(windowStart) >= interval {
+				counters = make(map[string]int)
+				windowStart = time.Now()
+			}
+			exceeded := counters[ip] >= limit
+			if !exceeded {
+				counters[ip]++
+			}
+			mu.Unlock()
+
+			if exceeded {
+				http.Error(w, "请求过于频繁", http.StatusTooManyRequests)
+				return
 			}
 			next.ServeHTTP(w, r)
 		})
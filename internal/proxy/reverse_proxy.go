@@ -2,16 +2,19 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go-load-balancer/internal/algorithms"
 	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/clientip"
 	"go-load-balancer/internal/stats"
 	"io"
 	"log"
 	"net"
 	"net/http"
 	"net/http/httputil"
-	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,17 +22,25 @@ import (
 type ReverseProxy struct {
 	backendPool    *backend.Pool
 	proxy          *httputil.ReverseProxy
-	algorithm      algorithms.Algorithm
+	algorithm      atomic.Pointer[algorithms.Algorithm] // 当前使用的算法，可在运行时替换
 	statsCollector stats.StatsCollector
+	errHandler     *ErrorHandler
+	loadParser     *loadReportParser // 为nil时不解析后端负载上报
+	clientIPs      *clientip.Resolver
+	outliers       *backend.OutlierDetector // 为nil时不进行离群检测
+	breakers       *backend.CircuitBreaker  // 为nil时不启用熔断
+	errorPenalty   time.Duration            // 转发失败时记录的最小延迟样本
 }
 
 // NewReverseProxy 创建新的反向代理实例
 func NewReverseProxy(pool *backend.Pool, algorithm algorithms.Algorithm, collector stats.StatsCollector) *ReverseProxy {
 	rp := &ReverseProxy{
 		backendPool:    pool,
-		algorithm:      algorithm,
 		statsCollector: collector,
+		clientIPs:      &clientip.Resolver{}, // 默认不信任任何代理
 	}
+	rp.algorithm.Store(&algorithm)
+	rp.errHandler = NewErrorHandler(rp)
 
 	transport := &http.Transport{
 		ResponseHeaderTimeout: 5 * time.Second,
@@ -45,6 +56,9 @@ func NewReverseProxy(pool *backend.Pool, algorithm algorithms.Algorithm, collect
 		}).DialContext,
 	}
 
+	// 转发失败的后端至少按一次响应超时计入延迟，避免基于延迟的算法继续偏向它
+	rp.errorPenalty = transport.ResponseHeaderTimeout
+
 	rp.proxy = &httputil.ReverseProxy{
 		Director:       rp.director,
 		ModifyResponse: rp.modifyResponse,
@@ -56,6 +70,52 @@ func NewReverseProxy(pool *backend.Pool, algorithm algorithms.Algorithm, collect
 	return rp
 }
 
+// EnableLoadReports 启用后端负载上报：解析响应头中的利用率以调整后端有效权重，
+// 并在响应返回给客户端之前删除该响应头
+func (rp *ReverseProxy) EnableLoadReports(opts LoadReportOptions) error {
+	parser, err := newLoadReportParser(opts)
+	if err != nil {
+		return err
+	}
+	rp.loadParser = parser
+	return nil
+}
+
+// SetClientIPResolver 设置客户端IP解析器，只有受信任代理转发的请求才会读取转发头
+func (rp *ReverseProxy) SetClientIPResolver(resolver *clientip.Resolver) {
+	rp.clientIPs = resolver
+}
+
+// ClientIPResolver 返回客户端IP解析器，供代理之前的中间件使用同一解析结果
+func (rp *ReverseProxy) ClientIPResolver() *clientip.Resolver {
+	return rp.clientIPs
+}
+
+// SetOutlierDetector 设置离群检测器，由真实请求的响应结果驱动
+func (rp *ReverseProxy) SetOutlierDetector(detector *backend.OutlierDetector) {
+	rp.outliers = detector
+}
+
+// SetCircuitBreaker 设置后端熔断器
+func (rp *ReverseProxy) SetCircuitBreaker(breaker *backend.CircuitBreaker) {
+	rp.breakers = breaker
+}
+
+// Algorithm 返回当前使用的负载均衡算法
+func (rp *ReverseProxy) Algorithm() algorithms.Algorithm {
+	return *rp.algorithm.Load()
+}
+
+// SetAlgorithm 原子替换负载均衡算法并返回原算法
+//
+// 新请求立即使用新算法，已经选择后端的请求仍由原算法处理响应。
+// 后端对象在新旧算法之间共享，连接数、延迟等统计不受影响。
+func (rp *ReverseProxy) SetAlgorithm(alg algorithms.Algorithm) algorithms.Algorithm {
+	prev := *rp.algorithm.Swap(&alg)
+	log.Printf("负载均衡算法已切换: %s -> %s", prev.Name(), alg.Name())
+	return prev
+}
+
 // ServeHTTP 实现http.Handler接口
 func (rp *ReverseProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	startTime := time.Now()
@@ -65,45 +125,127 @@ func (rp *ReverseProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	reqID := fmt.Sprintf("%v", time.Now().UnixNano())
 	ctx = context.WithValue(ctx, "req_id", reqID)
 	ctx = context.WithValue(ctx, "start_time", startTime)
+
+	// 真实客户端IP已由ClientIPMiddleware解析并记录到上下文
+	clientIP := clientip.FromRequest(r)
 	r = r.WithContext(ctx)
 
-	// 设置请求到算法中(针对IP哈希等需要请求信息的算法)
-	rp.algorithm.SetRequest(r)
+	// 为当前请求选择后端，请求信息只通过参数传递给算法，保证并发安全
+	alg := rp.Algorithm()
+	peer, probe, err := rp.pickBackend(ctx, r, alg)
+	if err != nil {
+		log.Printf("选择后端失败(%s, 客户端 %s): %v", alg.Name(), clientIP, err)
+		if rp.statsCollector != nil {
+			rp.statsCollector.RecordError("none", "no_backend")
+		}
+		rp.errHandler.HandleError(w, r, ErrNoAvailableBackend)
+		return
+	}
+
+	// 记录后端和所用算法到上下文，以便后续处理(算法切换时请求仍由原算法处理响应)
+	ctx = context.WithValue(ctx, "backend", peer)
+	ctx = context.WithValue(ctx, "algorithm", alg)
+	ctx = context.WithValue(ctx, "circuit_probe", probe)
+	r = r.WithContext(ctx)
 
 	// 调用代理
 	rp.proxy.ServeHTTP(w, r)
 }
 
+// pickBackend 选择后端并占用熔断器名额，probe表示该请求是半开状态下的探测请求
+//
+// 熔断器拒绝所选后端时(如半开状态的探测名额已被并发请求占满)排除该后端重新选择，
+// 最多重试后端数量次。熔断状态已包含在后端存活状态中，重新选择时通常会跳过被拒绝的后端。
+func (rp *ReverseProxy) pickBackend(ctx context.Context, r *http.Request, alg algorithms.Algorithm) (*backend.Backend, bool, error) {
+	if rp.breakers == nil {
+		peer, err := alg.Pick(ctx, r)
+		return peer, false, err
+	}
+
+	var refused map[*backend.Backend]bool
+	attempts := 0 // 首次被拒绝时才统计后端数量作为重试上限
+	for {
+		peer, err := alg.Pick(ctx, r)
+		if err != nil {
+			return nil, false, err
+		}
+		if !refused[peer] {
+			if probe, ok := rp.breakers.Acquire(peer); ok {
+				return peer, probe, nil
+			}
+			if rp.statsCollector != nil {
+				rp.statsCollector.RecordError(peer.URL.Host, "circuit_open")
+			}
+		}
+
+		if refused == nil {
+			refused = make(map[*backend.Backend]bool)
+			attempts = len(rp.backendPool.GetBackends())
+		}
+		refused[peer] = true
+		if attempts--; attempts <= 0 {
+			return nil, false, fmt.Errorf("熔断器拒绝了选中的后端")
+		}
+	}
+}
+
+// backendFromContext 获取请求上下文中记录的后端
+func backendFromContext(ctx context.Context) *backend.Backend {
+	if peer, ok := ctx.Value("backend").(*backend.Backend); ok {
+		return peer
+	}
+	return nil
+}
+
+// isCircuitProbe 判断请求是否为熔断器半开状态下的探测请求
+func isCircuitProbe(ctx context.Context) bool {
+	probe, _ := ctx.Value("circuit_probe").(bool)
+	return probe
+}
+
 // director 修改请求以发送到后端
 func (rp *ReverseProxy) director(req *http.Request) {
-	ctx, cancel := context.WithTimeout(req.Context(), 5*time.Second)
-	defer cancel()
-	req = req.WithContext(ctx)
-
-	// 使用负载均衡算法获取下一个后端
-	peer := rp.algorithm.GetNextBackend()
+	// 后端已在ServeHTTP中选择好
+	peer := backendFromContext(req.Context())
 	if peer == nil {
 		log.Printf("无可用后端服务器")
 		return
 	}
 
-	if !peer.IsAlive() {
-		log.Printf("后端服务器 %s 不可用", peer.URL.Host)
-		return
-	}
-
 	req.URL.Scheme = peer.URL.Scheme
 	req.URL.Host = peer.URL.Host
-	req.Header.Set("X-Forwarded-For", req.RemoteAddr)
 
-	// 记录后端信息到上下文，以便后续处理
-	ctx = context.WithValue(req.Context(), "backend", peer.URL.Host)
-	req = req.WithContext(ctx)
+	// 只转发受信任的对端发送的、所配置的那一种转发头，其余转发头可能是伪造的，直接丢弃
+	peerIP := clientip.RemoteIP(req)
+	trusted := rp.clientIPs.IsTrusted(peerIP)
+	if rp.clientIPs.Header() == clientip.HeaderForwarded {
+		if !trusted {
+			req.Header.Del("Forwarded")
+		}
+		req.Header.Add("Forwarded", "for="+forwardedNode(peerIP))
+		// 值为nil时httputil.ReverseProxy不会生成X-Forwarded-For
+		req.Header["X-Forwarded-For"] = nil
+	} else {
+		// httputil.ReverseProxy随后会将对端地址追加到X-Forwarded-For
+		if !trusted {
+			req.Header.Del("X-Forwarded-For")
+		}
+		req.Header.Del("Forwarded")
+	}
+	req.Header.Set("X-Real-IP", clientip.FromRequest(req))
 
 	// 增加连接计数
 	peer.IncrementConnections()
 }
 
+// forwardedNode 按RFC 7239格式化Forwarded头的for参数，IPv6地址需加方括号和引号
+func forwardedNode(ip string) string {
+	if strings.Contains(ip, ":") {
+		return `"[` + ip + `]"`
+	}
+	return ip
+}
+
 // modifyResponse 修改来自后端的响应
 func (rp *ReverseProxy) modifyResponse(res *http.Response) error {
 	// 如果请求为空，直接返回
@@ -120,54 +262,89 @@ func (rp *ReverseProxy) modifyResponse(res *http.Response) error {
 	}
 
 	// 获取后端信息
-	var backendHost string
-	if v := res.Request.Context().Value("backend"); v != nil {
-		if s, ok := v.(string); ok {
-			backendHost = s
-		}
+	peer := backendFromContext(res.Request.Context())
+	if peer == nil {
+		return nil
 	}
 
-	// 减少后端连接数并记录统计信息
-	if backendURL, err := url.Parse(res.Request.URL.String()); err == nil {
-		if peers := rp.backendPool.GetBackends(); peers != nil {
-			for _, p := range peers {
-				if p.URL.Host == backendURL.Host {
-					p.DecrementConnections()
+	// 减少后端连接数
+	peer.DecrementConnections()
 
-					// 记录请求统计
-					if rp.statsCollector != nil && startTime != (time.Time{}) {
-						duration := time.Since(startTime)
-						rp.statsCollector.RecordRequest(backendHost, res.StatusCode, res.Request.Method, duration)
-					}
+	// 根据响应状态码进行离群检测
+	if rp.outliers != nil {
+		rp.outliers.ObserveResponse(peer, res.StatusCode)
+	}
 
-					break
-				}
+	// 更新熔断器，响应时间只统计到首字节
+	if rp.breakers != nil {
+		var elapsed time.Duration
+		if !startTime.IsZero() {
+			elapsed = time.Since(startTime)
+		}
+		rp.breakers.ObserveResponse(peer, isCircuitProbe(res.Request.Context()), res.StatusCode, elapsed)
+	}
+
+	// 解析后端负载上报并从响应中删除
+	if rp.loadParser != nil {
+		if value := res.Header.Get(rp.loadParser.header); value != "" {
+			if load, ok := rp.loadParser.parse(value); ok {
+				peer.ReportLoad(load)
 			}
+			res.Header.Del(rp.loadParser.header)
+		}
+	}
+
+	// 交给需要观察响应的算法处理(如会话保持写入Cookie)
+	alg, ok := res.Request.Context().Value("algorithm").(algorithms.Algorithm)
+	if !ok {
+		alg = rp.Algorithm()
+	}
+	if hook, ok := alg.(algorithms.ResponseHook); ok {
+		hook.OnResponse(res, peer)
+	}
+
+	if startTime != (time.Time{}) {
+		duration := time.Since(startTime)
+
+		// 记录后端延迟，供基于延迟的算法使用
+		peer.ObserveLatency(duration)
+
+		// 记录请求统计
+		if rp.statsCollector != nil {
+			rp.statsCollector.RecordRequest(peer.URL.Host, res.StatusCode, res.Request.Method, duration)
 		}
 	}
+
 	return nil
 }
 
 // errorHandler 处理代理错误
 func (rp *ReverseProxy) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
-	log.Printf("代理错误: %v", err)
+	log.Printf("代理错误(客户端 %s): %v", clientip.FromRequest(r), err)
 
 	// 确保释放后端连接
-	if backendURL, err := url.Parse(r.URL.String()); err == nil {
-		if peers := rp.backendPool.GetBackends(); peers != nil {
-			for _, p := range peers {
-				if p.URL.Host == backendURL.Host {
-					p.DecrementConnections()
-
-					// 记录错误
-					if rp.statsCollector != nil {
-						backendHost := p.URL.Host
-						rp.statsCollector.RecordError(backendHost, "proxy_error")
-					}
-
-					break
-				}
+	if peer := backendFromContext(r.Context()); peer != nil {
+		peer.DecrementConnections()
+
+		// 客户端主动取消的请求不代表后端异常
+		if !errors.Is(err, context.Canceled) {
+			if rp.outliers != nil {
+				rp.outliers.ObserveError(peer)
 			}
+			// 记录惩罚延迟样本：取已耗时与传输超时中的较大者
+			penalty := rp.errorPenalty
+			if startTime, ok := r.Context().Value("start_time").(time.Time); ok {
+				penalty = max(penalty, time.Since(startTime))
+			}
+			peer.ObserveLatency(penalty)
+		}
+		if rp.breakers != nil {
+			rp.breakers.ObserveError(peer, isCircuitProbe(r.Context()), err)
+		}
+
+		// 记录错误
+		if rp.statsCollector != nil {
+			rp.statsCollector.RecordError(peer.URL.Host, "proxy_error")
 		}
 	}
 
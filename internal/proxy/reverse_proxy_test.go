@@ -0,0 +1,160 @@
+package proxy
+
+import (
+	"context"
+	"go-load-balancer/internal/algorithms"
+	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/clientip"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestBackend 创建指向指定地址的后端
+func newTestBackend(t *testing.T, rawURL string) *backend.Backend {
+	t.Helper()
+	b, err := backend.NewBackend(rawURL, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// refusedURL 返回一个已关闭监听的地址，连接会被立即拒绝
+func refusedURL(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return "http://" + addr
+}
+
+// serve 通过代理发送一个请求并返回响应状态码
+func serve(rp *ReverseProxy) int {
+	rec := httptest.NewRecorder()
+	rp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec.Code
+}
+
+func TestErrorPenaltyStopsPreferringFailingBackend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	good := newTestBackend(t, srv.URL)
+	bad := newTestBackend(t, refusedURL(t))
+	backends := []*backend.Backend{good, bad}
+	rp := NewReverseProxy(backend.NewPool(backends), algorithms.NewPeakEWMA(backends), nil)
+
+	// 两个后端初始都没有延迟样本，发送请求直到失败的后端被选中一次
+	failed := false
+	for i := 0; i < 50 && !failed; i++ {
+		failed = serve(rp) == http.StatusBadGateway
+	}
+	if !failed {
+		t.Fatal("失败的后端始终未被选中")
+	}
+	if bad.PeakEWMA() < rp.errorPenalty/2 {
+		t.Fatalf("失败后端的延迟样本为 %v，期望不低于惩罚延迟 %v", bad.PeakEWMA(), rp.errorPenalty)
+	}
+	if bad.GetConnections() != 0 {
+		t.Fatalf("失败后端的连接数未释放: %d", bad.GetConnections())
+	}
+
+	// 惩罚延迟生效后，请求应全部转发到正常的后端
+	for i := 0; i < 20; i++ {
+		if code := serve(rp); code != http.StatusOK {
+			t.Fatalf("第%d个请求返回 %d，失败的后端仍被选中", i, code)
+		}
+	}
+}
+
+func TestServeHTTPUsesClientIPFromMiddleware(t *testing.T) {
+	realIP := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		realIP <- r.Header.Get("X-Real-IP")
+	}))
+	defer srv.Close()
+
+	b := newTestBackend(t, srv.URL)
+	backends := []*backend.Backend{b}
+	rp := NewReverseProxy(backend.NewPool(backends), algorithms.NewRoundRobin(backends), nil)
+	resolver, err := clientip.NewResolver([]string{"10.0.0.0/8"}, clientip.HeaderXFF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp.SetClientIPResolver(resolver)
+	handler := ChainMiddleware(rp, ClientIPMiddleware(resolver))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got := <-realIP; got != "1.2.3.4" {
+		t.Errorf("后端收到的X-Real-IP为 %s，期望 1.2.3.4", got)
+	}
+
+	// 代理直接使用上下文中已记录的客户端IP，不再重新解析请求头
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req = req.WithContext(clientip.WithClientIP(req.Context(), "5.6.7.8"))
+	rp.ServeHTTP(httptest.NewRecorder(), req)
+	if got := <-realIP; got != "5.6.7.8" {
+		t.Errorf("后端收到的X-Real-IP为 %s，期望上下文中记录的 5.6.7.8", got)
+	}
+}
+
+// sequenceAlgorithm 按固定顺序返回后端且不检查存活状态，用于模拟选择与熔断检查之间的竞争
+type sequenceAlgorithm struct {
+	backends []*backend.Backend
+	next     int
+}
+
+func (s *sequenceAlgorithm) Pick(ctx context.Context, req *http.Request) (*backend.Backend, error) {
+	b := s.backends[s.next%len(s.backends)]
+	s.next++
+	return b, nil
+}
+
+func (s *sequenceAlgorithm) Name() string {
+	return "sequence"
+}
+
+func TestCircuitRefusalRepicksOtherBackend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	open := newTestBackend(t, refusedURL(t))
+	good := newTestBackend(t, srv.URL)
+	backends := []*backend.Backend{open, good}
+	pool := backend.NewPool(backends)
+	breakers := backend.NewCircuitBreaker(pool, backend.CircuitBreakerConfig{MinRequests: 1, OpenDuration: time.Hour})
+	breakers.ObserveResponse(open, false, http.StatusInternalServerError, 0)
+	if open.CircuitState() != backend.CircuitOpen {
+		t.Fatalf("熔断器状态为 %s，期望 %s", open.CircuitState(), backend.CircuitOpen)
+	}
+
+	alg := &sequenceAlgorithm{backends: backends}
+	rp := NewReverseProxy(pool, alg, nil)
+	rp.SetCircuitBreaker(breakers)
+	if code := serve(rp); code != http.StatusOK {
+		t.Fatalf("熔断器拒绝首选后端后返回 %d，期望重新选择其他后端", code)
+	}
+	if alg.next != 2 {
+		t.Errorf("选择了%d次后端，期望2次", alg.next)
+	}
+
+	// 所有后端都被熔断时在有限次数内失败
+	breakers.ObserveResponse(good, false, http.StatusInternalServerError, 0)
+	alg.next = 0
+	if code := serve(rp); code != http.StatusServiceUnavailable {
+		t.Fatalf("全部后端熔断时返回 %d，期望 %d", code, http.StatusServiceUnavailable)
+	}
+	if alg.next > len(backends)+1 {
+		t.Errorf("全部后端熔断时选择了%d次后端，超过后端数量", alg.next)
+	}
+}
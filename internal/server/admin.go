@@ -0,0 +1,56 @@
+package server
+
+import (
+	"crypto/subtle"
+	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/config"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// newAdminServer 创建只提供管理接口的HTTP服务器，未配置admin_addr时返回nil
+//
+// 管理接口可以改变流量分配，因此不挂载在代理的监听地址上。
+func newAdminServer(cfg *config.LBConfig, switcher *algorithmSwitcher, checker *backend.HealthChecker) *http.Server {
+	if cfg.AdminAddr == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+
+	// 添加运行时切换算法的管理端点
+	mux.Handle("/admin/algorithm", switcher)
+
+	// 添加立即重新检查后端的管理端点
+	mux.Handle("/admin/backends/recheck", &recheckHandler{checker: checker})
+
+	return &http.Server{
+		Addr:    cfg.AdminAddr,
+		Handler: requireAdminToken(cfg.AdminToken, mux),
+	}
+}
+
+// serveAdmin 在后台运行管理接口服务器
+func serveAdmin(srv *http.Server) {
+	log.Printf("管理接口已启动，监听地址: %s\n", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Printf("管理接口启动失败: %v", err)
+	}
+}
+
+// requireAdminToken 要求请求携带 Authorization: Bearer <token>，token为空时不校验
+func requireAdminToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "未授权", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
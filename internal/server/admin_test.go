@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestAdminHandler 创建配置了令牌的管理接口处理器
+func newTestAdminHandler(t *testing.T, token string) http.Handler {
+	t.Helper()
+	cfg := newTestConfig()
+	cfg.AdminAddr = "127.0.0.1:0"
+	cfg.AdminToken = token
+	s := newTestSwitcher(t, cfg)
+	srv := newAdminServer(cfg, s, newHealthChecker(cfg, s.pool))
+	if srv == nil {
+		t.Fatal("配置了admin_addr时未创建管理接口")
+	}
+	return srv.Handler
+}
+
+func TestAdminRecheckRequiresToken(t *testing.T) {
+	handler := newTestAdminHandler(t, "s3cret")
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{"缺少令牌", "", http.StatusUnauthorized},
+		{"令牌错误", "Bearer wrong", http.StatusUnauthorized},
+		{"令牌前缀相同", "Bearer s3cret-extra", http.StatusUnauthorized},
+		{"认证方式错误", "Basic s3cret", http.StatusUnauthorized},
+		{"缺少Bearer前缀", "s3cret", http.StatusUnauthorized},
+		{"令牌正确", "Bearer s3cret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/backends/recheck", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("响应状态码为 %d，期望 %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized {
+				if rec.Header().Get("WWW-Authenticate") != "Bearer" {
+					t.Error("未授权的响应缺少WWW-Authenticate头")
+				}
+				return
+			}
+			// 没有被隔离的后端时返回空列表
+			var results []recheckResult
+			if err := json.NewDecoder(rec.Body).Decode(&results); err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 0 {
+				t.Errorf("重新检查了%d个后端，期望0个", len(results))
+			}
+		})
+	}
+}
+
+func TestAdminTokenProtectsAllEndpoints(t *testing.T) {
+	handler := newTestAdminHandler(t, "s3cret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/algorithm", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("未携带令牌访问算法接口返回 %d，期望 %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAdminWithoutToken(t *testing.T) {
+	handler := newTestAdminHandler(t, "")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/backends/recheck", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("未配置令牌时返回 %d，期望 %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestAdminDisabledWithoutAddr(t *testing.T) {
+	cfg := newTestConfig()
+	s := newTestSwitcher(t, cfg)
+	if srv := newAdminServer(cfg, s, newHealthChecker(cfg, s.pool)); srv != nil {
+		t.Error("未配置admin_addr时仍创建了管理接口")
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"fmt"
+	"go-load-balancer/internal/algorithms"
+	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/config"
+	"go-load-balancer/internal/proxy"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// newAlgorithm 根据配置创建负载均衡算法
+//
+// 启用子集划分时每个分组只在本实例的后端子集上运行所配置的算法；
+// 配置了本实例所在可用区时按可用区分别创建算法实例；后端分布在多个优先级层级时，
+// 每个层级再独立进行可用区感知路由；启用会话保持时最后对其进行包装。
+// backends需与cfg.Servers一一对应；prev为运行时切换前的算法，用于沿用会话保持状态，首次创建时为nil。
+func newAlgorithm(cfg *config.LBConfig, backends []*backend.Backend, prev algorithms.Algorithm) (algorithms.Algorithm, error) {
+	opts := algorithms.Options(cfg.AlgorithmOptions)
+	create := func(backends []*backend.Backend) (algorithms.Algorithm, error) {
+		return algorithms.CreateAlgorithm(cfg.Algorithm, backends, opts)
+	}
+
+	if cfg.Subset.InstanceCount > 0 {
+		subsetOpts := algorithms.SubsetOptions{
+			InstanceID:    cfg.Subset.InstanceID,
+			InstanceCount: cfg.Subset.InstanceCount,
+			Size:          cfg.Subset.Size,
+			Method:        strings.ToLower(cfg.Subset.Method),
+		}
+		createInSubset := create
+		create = func(backends []*backend.Backend) (algorithms.Algorithm, error) {
+			return algorithms.NewSubset(backends, subsetOpts, createInSubset)
+		}
+	}
+
+	if cfg.Zone != "" {
+		zoneOpts := algorithms.ZoneOptions{
+			LocalZone:         cfg.Zone,
+			MinHealthyPercent: cfg.ZoneRouting.MinHealthyPercent,
+			OverloadFactor:    cfg.ZoneRouting.OverloadFactor,
+		}
+		createInZone := create
+		create = func(backends []*backend.Backend) (algorithms.Algorithm, error) {
+			return algorithms.NewZoneAware(backends, zoneOpts, createInZone)
+		}
+	}
+
+	var alg algorithms.Algorithm
+	var err error
+	if tiers := newTiers(cfg, backends); len(tiers) > 1 {
+		alg, err = algorithms.NewTiered(tiers, cfg.Failover.MinHealthyPercent, create)
+	} else {
+		alg, err = create(backends)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.Sticky.Enabled {
+		return alg, nil
+	}
+	return algorithms.NewStickyFrom(prev, alg, backends, newStickyOptions(&cfg.Sticky))
+}
+
+// newTiers 按优先级将后端分组，备用服务器排在所有非备用层级之后
+func newTiers(cfg *config.LBConfig, backends []*backend.Backend) []algorithms.Tier {
+	type tierKey struct {
+		backup   bool
+		priority int
+	}
+
+	groups := make(map[tierKey][]*backend.Backend)
+	var keys []tierKey
+	for i, s := range cfg.Servers {
+		key := tierKey{backup: s.Backup, priority: s.Priority}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], backends[i])
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].backup != keys[j].backup {
+			return !keys[i].backup
+		}
+		return keys[i].priority < keys[j].priority
+	})
+
+	tiers := make([]algorithms.Tier, 0, len(keys))
+	for _, key := range keys {
+		name := fmt.Sprintf("priority-%d", key.priority)
+		if key.backup {
+			name = "backup-" + name
+		}
+		tiers = append(tiers, algorithms.Tier{Name: name, Backends: groups[key]})
+	}
+	return tiers
+}
+
+// watchMembership 将后端池的活跃后端变化转发给反向代理当前使用的算法
+func watchMembership(pool *backend.Pool, rp *proxy.ReverseProxy) {
+	pool.OnChange(func(active []*backend.Backend) {
+		if l, ok := rp.Algorithm().(algorithms.MembershipListener); ok {
+			l.UpdateBackends(active)
+		}
+	})
+}
+
+// newStickyOptions 将配置中的会话保持参数转换为算法选项
+func newStickyOptions(sc *config.StickyConfig) algorithms.StickyOptions {
+	ttl, _ := time.ParseDuration(sc.TTL)
+
+	sameSite := http.SameSiteLaxMode
+	switch strings.ToLower(sc.SameSite) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	}
+
+	return algorithms.StickyOptions{
+		Mode:        strings.ToLower(sc.Mode),
+		CookieName:  sc.CookieName,
+		TTL:         ttl,
+		SameSite:    sameSite,
+		Secure:      sc.Secure,
+		HTTPOnly:    sc.HTTPOnly,
+		Secret:      sc.Secret,
+		MaxSessions: sc.MaxSessions,
+	}
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"fmt"
+	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/config"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// newBackends 根据配置创建后端列表
+func newBackends(cfg *config.LBConfig) ([]*backend.Backend, error) {
+	slowStart := newSlowStart(cfg)
+	loadFeedback := newLoadFeedback(cfg)
+
+	backends := make([]*backend.Backend, 0, len(cfg.Servers))
+	for _, s := range cfg.Servers {
+		b, err := backend.NewBackend(s.URL, s.Weight)
+		if err != nil {
+			return nil, err
+		}
+		// 设置健康检查路径
+		b.HealthCheckPath = s.HealthCheckPath
+		b.Zone = s.Zone
+		b.SetSlowStart(slowStart)
+		b.SetLoadFeedback(loadFeedback)
+		b.SetHealthThresholds(healthThreshold(s.Rise, cfg.HealthCheck.Rise), healthThreshold(s.Fall, cfg.HealthCheck.Fall))
+		if s.HealthCheck != nil {
+			probe, err := newProbe(cfg, s)
+			if err != nil {
+				return nil, fmt.Errorf("后端 %s 的健康检查配置无效: %v", s.URL, err)
+			}
+			b.SetProbe(probe)
+		}
+		backends = append(backends, b)
+	}
+	return backends, nil
+}
+
+// healthThreshold 返回后端的健康检查阈值，后端未单独配置时沿用全局配置
+func healthThreshold(server, global int) int {
+	if server > 0 {
+		return server
+	}
+	return global
+}
+
+// newProbe 将后端的健康检查配置转换为健康探测
+func newProbe(cfg *config.LBConfig, s config.ServerConfig) (backend.Probe, error) {
+	hc := s.HealthCheck
+
+	// 未指定协议时，启用TLS或后端为https时使用TLS
+	scheme := strings.ToLower(hc.Scheme)
+	if scheme == "" && (hc.TLS.Enabled || strings.HasPrefix(s.URL, "https://")) {
+		scheme = "https"
+	}
+
+	switch strings.ToLower(hc.Type) {
+	case "grpc":
+		return backend.NewGRPCProbe(backend.GRPCCheck{
+			Service:   hc.Service,
+			Port:      hc.Port,
+			Authority: hc.Host,
+			UseTLS:    scheme == "https",
+			TLS:       newProbeTLS(hc.TLS),
+		})
+	case "tcp":
+		send, err := hc.SendBytes()
+		if err != nil {
+			return nil, err
+		}
+		expect, err := hc.ExpectBytes()
+		if err != nil {
+			return nil, err
+		}
+		return backend.NewTCPProbe(backend.TCPCheck{
+			Port:        hc.Port,
+			Send:        send,
+			Expect:      expect,
+			ExpectRegex: hc.ExpectRegex,
+			UseTLS:      scheme == "https",
+			TLS:         newProbeTLS(hc.TLS),
+		})
+	case "exec":
+		return backend.NewExecProbe(backend.ExecCheck{
+			Command: hc.Command,
+			Port:    hc.Port,
+		})
+	default:
+		path := hc.Path
+		if path == "" {
+			path = s.HealthCheckPath
+		}
+		if path == "" {
+			path = cfg.HealthCheck.Path
+		}
+
+		headers := make(http.Header, len(hc.Headers))
+		for name, value := range hc.Headers {
+			headers.Set(name, value)
+		}
+
+		return backend.NewHTTPProbe(backend.HTTPCheck{
+			Method:         hc.Method,
+			Path:           path,
+			Scheme:         scheme,
+			Port:           hc.Port,
+			Host:           hc.Host,
+			Headers:        headers,
+			Body:           hc.Body,
+			ExpectedStatus: hc.ExpectedStatus,
+			BodyContains:   hc.BodyContains,
+			BodyRegex:      hc.BodyRegex,
+			JSONPath:       hc.JSONPath,
+			JSONValue:      hc.JSONValue,
+			TLS:            newProbeTLS(hc.TLS),
+		})
+	}
+}
+
+// newProbeTLS 将配置中的TLS参数转换为健康探测的TLS设置
+func newProbeTLS(t config.HealthCheckTLSConfig) backend.ProbeTLS {
+	return backend.ProbeTLS{
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		ServerName:         t.ServerName,
+		CAFile:             t.CAFile,
+		CertFile:           t.CertFile,
+		KeyFile:            t.KeyFile,
+	}
+}
+
+// newSlowStart 将配置中的慢启动参数转换为后端慢启动设置
+func newSlowStart(cfg *config.LBConfig) backend.SlowStart {
+	duration, _ := time.ParseDuration(cfg.SlowStart.Duration)
+	return backend.SlowStart{
+		Duration: duration,
+		Mode:     strings.ToLower(cfg.SlowStart.Mode),
+		MinRatio: cfg.SlowStart.MinRatio,
+	}
+}
+
+// newLoadFeedback 将配置中的负载反馈参数转换为后端负载反馈设置
+func newLoadFeedback(cfg *config.LBConfig) backend.LoadFeedback {
+	window, _ := time.ParseDuration(cfg.LoadFeedback.Window)
+	return backend.LoadFeedback{
+		Window:   window,
+		MinRatio: cfg.LoadFeedback.MinRatio,
+		MaxRatio: cfg.LoadFeedback.MaxRatio,
+	}
+}
+
+// newOutlierDetection 将配置中的离群检测参数转换为后端离群检测设置
+func newOutlierDetection(cfg *config.LBConfig) backend.OutlierDetection {
+	oc := &cfg.OutlierDetection
+	interval, _ := time.ParseDuration(oc.Interval)
+	baseEjection, _ := time.ParseDuration(oc.BaseEjectionTime)
+	maxEjection, _ := time.ParseDuration(oc.MaxEjectionTime)
+	return backend.OutlierDetection{
+		Consecutive5xx:           oc.Consecutive5xx,
+		ConsecutiveGatewayErrors: oc.ConsecutiveGatewayErrors,
+		Interval:                 interval,
+		BaseEjectionTime:         baseEjection,
+		MaxEjectionTime:          maxEjection,
+		MaxEjectionPercent:       oc.MaxEjectionPercent,
+		SuccessRateMinHosts:      oc.SuccessRateMinHosts,
+		SuccessRateRequestVolume: oc.SuccessRateRequestVolume,
+		SuccessRateStdevFactor:   oc.SuccessRateStdevFactor,
+	}
+}
+
+// newCircuitBreaker 将配置中的熔断器参数转换为后端熔断器设置
+func newCircuitBreaker(cfg *config.LBConfig) backend.CircuitBreakerConfig {
+	cc := &cfg.CircuitBreaker
+	window, _ := time.ParseDuration(cc.Window)
+	slowCall, _ := time.ParseDuration(cc.SlowCallDuration)
+	openDuration, _ := time.ParseDuration(cc.OpenDuration)
+	return backend.CircuitBreakerConfig{
+		Window:           window,
+		MinRequests:      cc.MinRequests,
+		ErrorThreshold:   cc.ErrorThreshold,
+		TimeoutThreshold: cc.TimeoutThreshold,
+		SlowCallDuration: slowCall,
+		OpenDuration:     openDuration,
+		HalfOpenProbes:   cc.HalfOpenProbes,
+	}
+}
+
+// newHealthChecker 根据配置创建健康检查器
+func newHealthChecker(cfg *config.LBConfig, pool *backend.Pool) *backend.HealthChecker {
+	timeout, _ := time.ParseDuration(cfg.HealthCheck.Timeout)
+	retryInterval, _ := time.ParseDuration(cfg.HealthCheck.RetryInterval)
+	pool.SetMaxFailures(cfg.HealthCheck.MaxFailures)
+	backoff, _ := time.ParseDuration(cfg.HealthCheck.QuarantineBackoff)
+	maxBackoff, _ := time.ParseDuration(cfg.HealthCheck.QuarantineMaxBackoff)
+	pool.SetQuarantine(backend.Quarantine{Backoff: backoff, MaxBackoff: maxBackoff})
+	return backend.NewHealthChecker(pool, timeout, cfg.HealthCheck.RetryCount, retryInterval)
+}
@@ -5,7 +5,6 @@ package server
 import (
 	"context"
 	"fmt"
-	"go-load-balancer/internal/algorithms"
 	"go-load-balancer/internal/backend"
 	"go-load-balancer/internal/config"
 	"go-load-balancer/internal/proxy"
@@ -23,8 +22,10 @@ type httpServerImpl struct {
 	cfg            *config.LBConfig
 	proxy          *proxy.ReverseProxy
 	health         *backend.HealthChecker
+	outliers       *backend.OutlierDetector // 未启用离群检测时为nil
 	httpServer     *http.Server
-	algorithm      algorithms.Algorithm
+	adminServer    *http.Server // 未配置管理接口地址时为nil
+	switcher       *algorithmSwitcher
 	backendPool    *backend.Pool
 	statsCollector stats.StatsCollector
 	reporter       stats.Reporter
@@ -33,21 +34,15 @@ type httpServerImpl struct {
 // NewHTTPServer 创建新的HTTP服务器
 func NewHTTPServer(cfg *config.LBConfig) Server {
 	// 创建后端池
-	backends := make([]*backend.Backend, 0, len(cfg.Servers))
-	for _, s := range cfg.Servers {
-		b, err := backend.NewBackend(s.URL, s.Weight)
-		if err != nil {
-			log.Fatalf("创建后端失败: %v", err)
-		}
-		// 设置健康检查路径
-		b.HealthCheckPath = s.HealthCheckPath
-		backends = append(backends, b)
+	backends, err := newBackends(cfg)
+	if err != nil {
+		log.Fatalf("创建后端失败: %v", err)
 	}
 
 	pool := backend.NewPool(backends)
 
 	// 创建负载均衡算法
-	alg, err := algorithms.CreateAlgorithm(cfg.Algorithm, backends)
+	alg, err := newAlgorithm(cfg, backends, nil)
 	if err != nil {
 		log.Fatalf("创建负载均衡算法失败: %v", err)
 	}
@@ -61,17 +56,33 @@ func NewHTTPServer(cfg *config.LBConfig) Server {
 
 	// 创建反向代理
 	rp := proxy.NewReverseProxy(pool, alg, collector)
+	if err := configureProxy(rp, cfg); err != nil {
+		log.Fatalf("配置反向代理失败: %v", err)
+	}
+	watchMembership(pool, rp)
+
+	// 创建离群检测
+	var outliers *backend.OutlierDetector
+	if cfg.OutlierDetection.Enabled {
+		outliers = backend.NewOutlierDetector(pool, newOutlierDetection(cfg))
+		rp.SetOutlierDetector(outliers)
+	}
+
+	// 创建熔断器
+	if cfg.CircuitBreaker.Enabled {
+		rp.SetCircuitBreaker(backend.NewCircuitBreaker(pool, newCircuitBreaker(cfg)))
+	}
 
 	// 创建健康检查
-	timeout, _ := time.ParseDuration(cfg.HealthCheck.Timeout)
-	checker := backend.NewHealthChecker(pool, timeout, cfg.HealthCheck.RetryCount)
+	checker := newHealthChecker(cfg, pool)
 
 	return &httpServerImpl{
 		cfg:            cfg,
 		proxy:          rp,
 		health:         checker,
+		outliers:       outliers,
 		backendPool:    pool,
-		algorithm:      alg,
+		switcher:       newAlgorithmSwitcher(cfg, backends, pool, rp),
 		statsCollector: collector,
 		reporter:       reporter,
 	}
@@ -82,6 +93,9 @@ func (s *httpServerImpl) Start() error {
 	// 启动健康检查
 	interval, _ := time.ParseDuration(s.cfg.HealthCheck.Interval)
 	s.health.Start(interval)
+	if s.outliers != nil {
+		s.outliers.Start()
+	}
 
 	// 创建HTTP服务器
 	mux := http.NewServeMux()
@@ -101,10 +115,15 @@ func (s *httpServerImpl) Start() error {
 		w.Write([]byte("OK"))
 	})
 
-	// 创建HTTP服务器
+	// 创建HTTP服务器，客户端IP解析、访问日志、访问控制和限流作用于所有端点
 	s.httpServer = &http.Server{
 		Addr:    s.cfg.ListenAddr,
-		Handler: mux,
+		Handler: proxy.ChainMiddleware(mux, newMiddlewares(s.cfg, s.proxy.ClientIPResolver())...),
+	}
+
+	// 管理接口使用独立的监听地址
+	if s.adminServer = newAdminServer(s.cfg, s.switcher, s.health); s.adminServer != nil {
+		go serveAdmin(s.adminServer)
 	}
 
 	// 定期更新统计信息
@@ -167,10 +186,23 @@ func (rw *responseWriter) WriteHeader(statusCode int) {
 func (s *httpServerImpl) Stop() error {
 	// 先停止健康检查
 	s.health.Stop()
+	if s.outliers != nil {
+		s.outliers.Stop()
+	}
 
 	// 优雅关闭HTTP服务器
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if s.adminServer != nil {
+		if err := s.adminServer.Shutdown(ctx); err != nil {
+			log.Printf("管理接口关闭失败: %v", err)
+		}
+	}
 	return s.httpServer.Shutdown(ctx)
 }
+
+// Reload 应用新配置中与负载均衡算法相关的部分
+func (s *httpServerImpl) Reload(cfg *config.LBConfig) error {
+	return s.switcher.reload(cfg)
+}
@@ -6,6 +6,8 @@ import "go-load-balancer/internal/config"
 type Server interface {
 	Start() error
 	Stop() error
+	// Reload 在不重启的情况下应用新配置(目前为算法相关的配置)
+	Reload(cfg *config.LBConfig) error
 }
 
 // NewServerFunc 创建服务器的函数类型
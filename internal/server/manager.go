@@ -11,8 +11,9 @@ import (
 
 // ServerManager 管理多个服务器实例
 type ServerManager struct {
-	servers []Server
-	wg      sync.WaitGroup
+	servers    []Server
+	wg         sync.WaitGroup
+	configPath string // 收到SIGHUP时重新加载的配置文件路径
 }
 
 // NewServerManager 创建新的服务管理器
@@ -27,6 +28,11 @@ func (m *ServerManager) AddServer(server Server) {
 	m.servers = append(m.servers, server)
 }
 
+// SetConfigPath 设置收到SIGHUP信号时重新加载的配置文件路径
+func (m *ServerManager) SetConfigPath(path string) {
+	m.configPath = path
+}
+
 // StartAll 启动所有服务器
 func (m *ServerManager) StartAll() {
 	// 设置信号监听
@@ -44,6 +50,23 @@ func (m *ServerManager) StartAll() {
 		}(s)
 	}
 
+	// 收到SIGHUP时重新加载配置，StartAll返回时退出
+	reloadCh := make(chan os.Signal, 1)
+	signal.Notify(reloadCh, syscall.SIGHUP)
+	defer signal.Stop(reloadCh)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-reloadCh:
+				m.reload()
+			case <-done:
+				return
+			}
+		}
+	}()
+
 	// 等待终止信号
 	<-stopCh
 	log.Println("接收到终止信号，正在关闭服务器...")
@@ -59,6 +82,27 @@ func (m *ServerManager) StartAll() {
 	log.Println("所有服务器已关闭")
 }
 
+// reload 重新加载配置文件并应用到所有服务器，配置无效时保持当前配置
+func (m *ServerManager) reload() {
+	log.Println("接收到SIGHUP信号，重新加载配置...")
+
+	cfg, err := config.LoadConfig(m.configPath)
+	if err != nil {
+		log.Printf("重新加载配置失败: %v", err)
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Printf("新配置验证失败，保持当前配置: %v", err)
+		return
+	}
+
+	for _, s := range m.servers {
+		if err := s.Reload(cfg); err != nil {
+			log.Printf("应用新配置失败: %v", err)
+		}
+	}
+}
+
 // CreateFromConfig 从配置创建服务器
 func (m *ServerManager) CreateFromConfig(cfg *config.LBConfig) {
 	server := NewStandardHTTPServer(cfg)
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"go-load-balancer/internal/clientip"
+	"go-load-balancer/internal/config"
+	"go-load-balancer/internal/proxy"
+	"time"
+)
+
+// configureProxy 按配置设置反向代理的客户端IP解析和后端负载上报
+func configureProxy(rp *proxy.ReverseProxy, cfg *config.LBConfig) error {
+	resolver, err := clientip.NewResolver(cfg.TrustedProxies, cfg.ForwardedHeader)
+	if err != nil {
+		return err
+	}
+	rp.SetClientIPResolver(resolver)
+
+	if !cfg.LoadFeedback.Enabled {
+		return nil
+	}
+
+	header := cfg.LoadFeedback.Header
+	if header == "" {
+		header = "X-Backend-Load"
+	}
+	return rp.EnableLoadReports(proxy.LoadReportOptions{
+		Header: header,
+		Format: cfg.LoadFeedback.Format,
+		Metric: cfg.LoadFeedback.Metric,
+	})
+}
+
+// newMiddlewares 按配置创建代理监听地址上的中间件，各中间件使用同一客户端IP解析结果
+//
+// 配置已经过校验，这里不再处理解析错误。
+func newMiddlewares(cfg *config.LBConfig, resolver *clientip.Resolver) []proxy.Middleware {
+	middlewares := []proxy.Middleware{proxy.ClientIPMiddleware(resolver)}
+	if cfg.AccessLog {
+		middlewares = append(middlewares, proxy.LoggingMiddleware)
+	}
+	if len(cfg.ACL.Allow) > 0 || len(cfg.ACL.Deny) > 0 {
+		allow, _ := clientip.ParsePrefixes(cfg.ACL.Allow)
+		deny, _ := clientip.ParsePrefixes(cfg.ACL.Deny)
+		middlewares = append(middlewares, proxy.ACLMiddleware(allow, deny))
+	}
+	if cfg.RateLimit.Requests > 0 {
+		interval, _ := time.ParseDuration(cfg.RateLimit.Interval)
+		if interval <= 0 {
+			interval = time.Second
+		}
+		middlewares = append(middlewares, proxy.RateLimitMiddleware(cfg.RateLimit.Requests, interval))
+	}
+	return middlewares
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-load-balancer/internal/backend"
+	"net/http"
+)
+
+// recheckHandler 管理接口：立即重新检查后端，忽略隔离的退避时间
+type recheckHandler struct {
+	checker *backend.HealthChecker
+}
+
+// recheckRequest 重新检查的请求体，Backend为空时检查所有被隔离的后端
+type recheckRequest struct {
+	Backend string `json:"backend"`
+}
+
+// recheckResult 单个后端的检查结果
+type recheckResult struct {
+	Backend string `json:"backend"`
+	Status  string `json:"status"`
+}
+
+// ServeHTTP 处理POST请求，返回被检查后端检查后的状态
+func (h *recheckHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req recheckRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("无效的请求体: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	checked, err := h.checker.Recheck(req.Backend)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	results := make([]recheckResult, 0, len(checked))
+	for _, b := range checked {
+		results = append(results, recheckResult{Backend: b.URL.String(), Status: b.GetStatus()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-load-balancer/internal/algorithms"
+	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/config"
+	"go-load-balancer/internal/proxy"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// algorithmSwitcher 在运行时切换反向代理使用的负载均衡算法
+//
+// 新算法基于同一组后端对象创建，连接数、延迟和慢启动等状态自然延续；
+// 会话保持参数不变时沿用原有的签名密钥和应用会话。
+type algorithmSwitcher struct {
+	mu       sync.Mutex
+	cfg      *config.LBConfig // 当前生效的配置
+	started  *config.LBConfig // 启动时的配置，需要重启才能生效的配置项以此为准
+	backends []*backend.Backend
+	pool     *backend.Pool
+	proxy    *proxy.ReverseProxy
+}
+
+// newAlgorithmSwitcher 创建算法切换器
+func newAlgorithmSwitcher(cfg *config.LBConfig, backends []*backend.Backend, pool *backend.Pool, rp *proxy.ReverseProxy) *algorithmSwitcher {
+	return &algorithmSwitcher{
+		cfg:      cfg,
+		started:  cfg,
+		backends: backends,
+		pool:     pool,
+		proxy:    rp,
+	}
+}
+
+// reload 按新配置重建并切换算法，同时更新各后端的健康检查方式和rise/fall阈值
+//
+// 应用算法相关的配置(算法及其参数、会话保持、优先级层级、可用区路由、子集划分)
+// 以及各后端的health_check、rise/fall，后端列表发生变化时返回错误，需要重启进程。
+// 其余只在启动时读取的配置发生变化时记录日志，提示需要重启才能生效。
+func (s *algorithmSwitcher) reload(cfg *config.LBConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := sameServers(s.cfg.Servers, cfg.Servers); err != nil {
+		return err
+	}
+
+	// 先创建所有健康探测，任一配置无效时不做任何修改
+	probes := make([]backend.Probe, len(cfg.Servers))
+	for i, server := range cfg.Servers {
+		if server.HealthCheck == nil {
+			continue
+		}
+		probe, err := newProbe(cfg, server)
+		if err != nil {
+			return fmt.Errorf("后端 %s 的健康检查配置无效: %v", server.URL, err)
+		}
+		probes[i] = probe
+	}
+
+	if err := s.applyLocked(cfg); err != nil {
+		return err
+	}
+	for i, b := range s.backends {
+		server := cfg.Servers[i]
+		b.SetHealthThresholds(healthThreshold(server.Rise, cfg.HealthCheck.Rise), healthThreshold(server.Fall, cfg.HealthCheck.Fall))
+		b.SetProbe(probes[i])
+	}
+
+	if pending := restartRequired(s.started, cfg); len(pending) > 0 {
+		log.Printf("以下配置发生变化但需要重启才能生效: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// switchTo 切换到指定算法，opts为nil时算法不变则沿用当前的算法参数，否则使用新算法的默认参数
+func (s *algorithmSwitcher) switchTo(name string, opts map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := *s.cfg
+	next.Algorithm = name
+	if opts != nil || !strings.EqualFold(name, s.cfg.Algorithm) {
+		// 各算法的参数不通用，切换到其他算法时不沿用当前参数
+		next.AlgorithmOptions = opts
+	}
+	if err := algorithms.ValidateOptions(next.Algorithm, next.AlgorithmOptions); err != nil {
+		return err
+	}
+	return s.applyLocked(&next)
+}
+
+// applyLocked 创建新算法并替换，调用方需持有锁
+func (s *algorithmSwitcher) applyLocked(cfg *config.LBConfig) error {
+	alg, err := newAlgorithm(cfg, s.backends, s.proxy.Algorithm())
+	if err != nil {
+		return fmt.Errorf("创建负载均衡算法失败: %v", err)
+	}
+
+	// 切换前同步当前活跃后端，使依赖成员变化的算法从正确的状态开始
+	if l, ok := alg.(algorithms.MembershipListener); ok {
+		l.UpdateBackends(s.pool.GetActiveBackends())
+	}
+	s.proxy.SetAlgorithm(alg)
+	s.cfg = cfg
+	return nil
+}
+
+// sameServers 检查后端列表是否未发生运行时无法应用的变化
+func sameServers(current, next []config.ServerConfig) error {
+	if len(current) != len(next) {
+		return fmt.Errorf("后端列表发生变化，需要重启才能生效")
+	}
+	for i := range current {
+		if current[i].URL != next[i].URL || current[i].Weight != next[i].Weight || current[i].Zone != next[i].Zone {
+			return fmt.Errorf("后端 %s 的配置发生变化，需要重启才能生效", next[i].URL)
+		}
+	}
+	return nil
+}
+
+// startupSettings 只在启动时读取、需要重启才能生效的配置项
+var startupSettings = []struct {
+	name  string
+	value func(cfg *config.LBConfig) interface{}
+}{
+	{"listen_addr", func(c *config.LBConfig) interface{} { return c.ListenAddr }},
+	{"admin_addr", func(c *config.LBConfig) interface{} { return c.AdminAddr }},
+	{"admin_token", func(c *config.LBConfig) interface{} { return c.AdminToken }},
+	{"slow_start", func(c *config.LBConfig) interface{} { return c.SlowStart }},
+	{"load_feedback", func(c *config.LBConfig) interface{} { return c.LoadFeedback }},
+	{"outlier_detection", func(c *config.LBConfig) interface{} { return c.OutlierDetection }},
+	{"circuit_breaker", func(c *config.LBConfig) interface{} { return c.CircuitBreaker }},
+	{"trusted_proxies", func(c *config.LBConfig) interface{} { return c.TrustedProxies }},
+	{"forwarded_header", func(c *config.LBConfig) interface{} { return c.ForwardedHeader }},
+	{"access_log", func(c *config.LBConfig) interface{} { return c.AccessLog }},
+	{"rate_limit", func(c *config.LBConfig) interface{} { return c.RateLimit }},
+	{"acl", func(c *config.LBConfig) interface{} { return c.ACL }},
+	{"health_check", func(c *config.LBConfig) interface{} {
+		// rise/fall在重新加载时应用到各后端
+		hc := c.HealthCheck
+		hc.Rise, hc.Fall = 0, 0
+		return hc
+	}},
+}
+
+// restartRequired 返回新配置中发生变化但需要重启才能生效的配置项
+func restartRequired(started, next *config.LBConfig) []string {
+	var changed []string
+	for _, setting := range startupSettings {
+		if !reflect.DeepEqual(setting.value(started), setting.value(next)) {
+			changed = append(changed, setting.name)
+		}
+	}
+	for i := range next.Servers {
+		if started.Servers[i].HealthCheckPath != next.Servers[i].HealthCheckPath {
+			changed = append(changed, fmt.Sprintf("后端 %s 的health_check_path", next.Servers[i].URL))
+		}
+	}
+	return changed
+}
+
+// algorithmRequest 切换算法的请求体
+type algorithmRequest struct {
+	Algorithm string                 `json:"algorithm"`
+	Options   map[string]interface{} `json:"options,omitempty"`
+}
+
+// algorithmResponse 当前算法信息
+type algorithmResponse struct {
+	Algorithm string   `json:"algorithm"`
+	Available []string `json:"available"`
+}
+
+// ServeHTTP 实现管理接口：GET返回当前算法，POST切换算法
+func (s *algorithmSwitcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req algorithmRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("无效的请求体: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := s.switchTo(req.Algorithm, req.Options); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(algorithmResponse{
+		Algorithm: s.proxy.Algorithm().Name(),
+		Available: algorithms.RegisteredAlgorithms(),
+	})
+}
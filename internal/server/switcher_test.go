@@ -0,0 +1,108 @@
+package server
+
+import (
+	"go-load-balancer/internal/backend"
+	"go-load-balancer/internal/config"
+	"go-load-balancer/internal/proxy"
+	"reflect"
+	"testing"
+)
+
+// newTestConfig 创建包含两个后端的轮询配置
+func newTestConfig() *config.LBConfig {
+	return &config.LBConfig{
+		ListenAddr: "127.0.0.1:8080",
+		Algorithm:  "round_robin",
+		Servers: []config.ServerConfig{
+			{URL: "http://127.0.0.1:8001", Weight: 1},
+			{URL: "http://127.0.0.1:8002", Weight: 1},
+		},
+		HealthCheck: config.HealthCheckConfig{Interval: "10s", Timeout: "2s", Path: "/health"},
+	}
+}
+
+// newTestSwitcher 按配置创建算法切换器
+func newTestSwitcher(t *testing.T, cfg *config.LBConfig) *algorithmSwitcher {
+	t.Helper()
+	backends, err := newBackends(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := backend.NewPool(backends)
+	alg, err := newAlgorithm(cfg, backends, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := proxy.NewReverseProxy(pool, alg, nil)
+	return newAlgorithmSwitcher(cfg, backends, pool, rp)
+}
+
+func TestReloadAppliesAlgorithm(t *testing.T) {
+	s := newTestSwitcher(t, newTestConfig())
+
+	next := newTestConfig()
+	next.Algorithm = "least_conn"
+	next.HealthCheck.Rise = 3
+	next.Servers[0].Fall = 2
+	next.Servers[1].HealthCheck = &config.ServerHealthCheckConfig{Type: "tcp"}
+	if err := s.reload(next); err != nil {
+		t.Fatal(err)
+	}
+	if name := s.proxy.Algorithm().Name(); name != "least_conn" {
+		t.Errorf("重新加载后算法为 %s，期望 least_conn", name)
+	}
+}
+
+func TestReloadRejectsServerChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *config.LBConfig)
+	}{
+		{"增加后端", func(c *config.LBConfig) {
+			c.Servers = append(c.Servers, config.ServerConfig{URL: "http://127.0.0.1:8003", Weight: 1})
+		}},
+		{"修改地址", func(c *config.LBConfig) { c.Servers[0].URL = "http://127.0.0.1:9001" }},
+		{"修改权重", func(c *config.LBConfig) { c.Servers[1].Weight = 5 }},
+		{"无效的健康检查", func(c *config.LBConfig) {
+			c.Servers[0].HealthCheck = &config.ServerHealthCheckConfig{Type: "tcp", ExpectHex: "zz"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSwitcher(t, newTestConfig())
+			next := newTestConfig()
+			next.Algorithm = "least_conn"
+			tt.modify(next)
+			if err := s.reload(next); err == nil {
+				t.Fatal("重新加载未返回错误")
+			}
+			if name := s.proxy.Algorithm().Name(); name != "round_robin" {
+				t.Errorf("重新加载失败后算法变为 %s", name)
+			}
+		})
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	started := newTestConfig()
+
+	next := newTestConfig()
+	next.Algorithm = "least_conn"
+	next.HealthCheck.Rise = 3
+	next.HealthCheck.Fall = 3
+	next.Servers[0].Rise = 2
+	next.Servers[0].HealthCheck = &config.ServerHealthCheckConfig{Type: "tcp"}
+	if got := restartRequired(started, next); len(got) != 0 {
+		t.Errorf("可在运行时应用的配置被列为需要重启: %v", got)
+	}
+
+	next.HealthCheck.Interval = "5s"
+	next.AdminToken = "secret"
+	next.SlowStart.Duration = "30s"
+	next.Servers[1].HealthCheckPath = "/ready"
+	want := []string{"admin_token", "slow_start", "health_check", "后端 http://127.0.0.1:8002 的health_check_path"}
+	if got := restartRequired(started, next); !reflect.DeepEqual(got, want) {
+		t.Errorf("需要重启的配置为 %v，期望 %v", got, want)
+	}
+}
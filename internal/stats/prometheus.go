@@ -36,8 +36,23 @@ type PrometheusCollector struct {
 	// 后端状态计数器
 	backendStatus *prometheus.GaugeVec
 
+	// 后端是否被离群检测驱逐
+	backendEjected *prometheus.GaugeVec
+
+	// 后端熔断器状态
+	circuitState *prometheus.GaugeVec
+
 	// 请求失败计数器
 	requestErrors *prometheus.CounterVec
+
+	// 有界负载哈希溢出计数器
+	hashSpills *prometheus.CounterVec
+
+	// 优先级层级切换计数器
+	tierSwitches *prometheus.CounterVec
+
+	// 当前使用的优先级层级
+	activeTier *prometheus.GaugeVec
 }
 
 // GetPrometheusCollector 获取PrometheusCollector单例
@@ -92,6 +107,26 @@ func newPrometheusCollector() *PrometheusCollector {
 			[]string{"backend", "url"},
 		),
 
+		// 后端是否被离群检测驱逐
+		backendEjected: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: MetricNamespace,
+				Name:      "backend_ejected",
+				Help:      "后端是否被离群检测驱逐(1=已驱逐, 0=正常)",
+			},
+			[]string{"backend"},
+		),
+
+		// 后端熔断器状态
+		circuitState: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: MetricNamespace,
+				Name:      "circuit_breaker_state",
+				Help:      "后端熔断器状态(0=关闭, 1=半开, 2=打开)",
+			},
+			[]string{"backend"},
+		),
+
 		// 请求失败计数器
 		requestErrors: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -101,6 +136,36 @@ func newPrometheusCollector() *PrometheusCollector {
 			},
 			[]string{"backend", "error_type"},
 		),
+
+		// 有界负载哈希溢出计数器
+		hashSpills: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: MetricNamespace,
+				Name:      "hash_spills_total",
+				Help:      "因首选后端超出负载上限而溢出到其他后端的键数",
+			},
+			[]string{"algorithm"},
+		),
+
+		// 优先级层级切换计数器
+		tierSwitches: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: MetricNamespace,
+				Name:      "tier_switches_total",
+				Help:      "优先级层级切换次数",
+			},
+			[]string{"from", "to"},
+		),
+
+		// 当前使用的优先级层级
+		activeTier: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: MetricNamespace,
+				Name:      "active_tier",
+				Help:      "当前使用的优先级层级(1=使用中, 0=未使用)",
+			},
+			[]string{"tier"},
+		),
 	}
 }
 
@@ -115,6 +180,22 @@ func (pc *PrometheusCollector) RecordError(backend string, errorType string) {
 	pc.requestErrors.WithLabelValues(backend, errorType).Inc()
 }
 
+// RecordHashSpill 记录一次有界负载哈希溢出
+func (pc *PrometheusCollector) RecordHashSpill(algorithm string) {
+	pc.hashSpills.WithLabelValues(algorithm).Inc()
+}
+
+// RecordTierSwitch 记录一次优先级层级切换
+func (pc *PrometheusCollector) RecordTierSwitch(from, to string) {
+	pc.tierSwitches.WithLabelValues(from, to).Inc()
+}
+
+// SetActiveTier 设置当前使用的优先级层级
+func (pc *PrometheusCollector) SetActiveTier(tier string) {
+	pc.activeTier.Reset()
+	pc.activeTier.WithLabelValues(tier).Set(1)
+}
+
 // UpdateBackendStatus 更新后端状态
 func (pc *PrometheusCollector) UpdateBackendStatus(backends []*backend.Backend) {
 	for _, b := range backends {
@@ -123,10 +204,28 @@ func (pc *PrometheusCollector) UpdateBackendStatus(backends []*backend.Backend)
 			isAlive = 1.0
 		}
 		pc.backendStatus.WithLabelValues(b.Addr(), b.URL.String()).Set(isAlive)
+		isEjected := 0.0
+		if b.IsEjected() {
+			isEjected = 1.0
+		}
+		pc.backendEjected.WithLabelValues(b.Addr()).Set(isEjected)
+		pc.circuitState.WithLabelValues(b.Addr()).Set(circuitStateValue(b.CircuitState()))
 		pc.activeConnections.WithLabelValues(b.Addr()).Set(float64(b.GetConnections()))
 	}
 }
 
+// circuitStateValue 将熔断器状态转换为指标值
+func circuitStateValue(state string) float64 {
+	switch state {
+	case backend.CircuitHalfOpen:
+		return 1
+	case backend.CircuitOpen:
+		return 2
+	default:
+		return 0
+	}
+}
+
 // GetPrometheusHandler 获取Prometheus HTTP处理器
 func GetPrometheusHandler() http.Handler {
 	return promhttp.Handler()
@@ -25,6 +25,14 @@ type BackendMetrics struct {
 	ActiveConnections int64         `json:"active_connections"`
 	TotalRequests     int64         `json:"total_requests"`
 	AvgResponseTime   time.Duration `json:"avg_response_time"`
+	LatencyEWMA       time.Duration `json:"latency_ewma"`
+	EffectiveWeight   float64       `json:"effective_weight"`
+	LoadFactor        float64       `json:"load_factor"`
+	ReportedLoad      float64       `json:"reported_load"`
+	OutlierEjections  int           `json:"outlier_ejections"`
+	CircuitState      string        `json:"circuit_state"`
+	QuarantinedUntil  *time.Time    `json:"quarantined_until,omitempty"` // 被隔离的后端下次探测的时间
+	HealthDetail      string        `json:"health_detail,omitempty"`     // 最近一次健康探测的详情(如脚本输出)或失败原因
 	LastChecked       time.Time     `json:"last_checked"`
 }
 
@@ -123,11 +131,25 @@ func (r *DefaultReporter) UpdateBackends(backends []*backend.Backend) {
 		// 更新状态
 		if b.IsAlive() {
 			r.backendMetrics[addr].Status = "healthy"
+		} else if status := b.GetStatus(); status == backend.StatusEjected || status == backend.StatusCircuitOpen {
+			r.backendMetrics[addr].Status = status
 		} else {
 			r.backendMetrics[addr].Status = "failed"
 		}
 
 		r.backendMetrics[addr].ActiveConnections = b.GetConnections()
+		r.backendMetrics[addr].AvgResponseTime = b.AvgTTFB()
+		r.backendMetrics[addr].LatencyEWMA = b.PeakEWMA()
+		r.backendMetrics[addr].EffectiveWeight = b.EffectiveWeight()
+		r.backendMetrics[addr].LoadFactor = b.LoadFactor()
+		r.backendMetrics[addr].ReportedLoad = b.ReportedLoad()
+		r.backendMetrics[addr].OutlierEjections = b.OutlierEjections()
+		r.backendMetrics[addr].CircuitState = b.CircuitState()
+		r.backendMetrics[addr].HealthDetail = b.HealthDetail()
+		r.backendMetrics[addr].QuarantinedUntil = nil
+		if until := b.QuarantinedUntil(); !until.IsZero() {
+			r.backendMetrics[addr].QuarantinedUntil = &until
+		}
 		r.backendMetrics[addr].LastChecked = time.Now()
 	}
 }